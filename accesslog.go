@@ -0,0 +1,229 @@
+package mows
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AccessLogFormat selects the output format of the AccessLog middleware.
+type AccessLogFormat int
+
+const (
+	// AccessLogJSON writes one JSON object per request.
+	AccessLogJSON AccessLogFormat = iota
+
+	// AccessLogLogfmt writes key=value pairs per request.
+	AccessLogLogfmt
+
+	// AccessLogCommon writes the Apache Common Log Format.
+	AccessLogCommon
+
+	// AccessLogCombined writes the Apache Combined Log Format, which adds
+	// the referer and user agent to the Common format.
+	AccessLogCombined
+)
+
+// DefaultRedactedHeaders lists headers whose values are never logged
+// when AccessLogConfig.RedactHeaders is empty.
+var DefaultRedactedHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+}
+
+// AccessLogConfig defines the configuration for the AccessLog middleware.
+type AccessLogConfig struct {
+	// Format selects the output format. Defaults to AccessLogJSON.
+	Format AccessLogFormat
+
+	// Output receives the log lines. Defaults to os.Stdout.
+	Output io.Writer
+
+	// SkipPaths lists request paths that are not logged, such as
+	// health checks.
+	SkipPaths []string
+
+	// Skip excludes requests from logging when it returns true.
+	Skip func(*Context) bool
+
+	// Headers lists request headers to include in JSON and logfmt output.
+	Headers []string
+
+	// RedactHeaders lists headers whose values are replaced with
+	// "[REDACTED]". Defaults to DefaultRedactedHeaders.
+	RedactHeaders []string
+}
+
+// accessLogEntry holds the fields of a single access log line.
+type accessLogEntry struct {
+	time      time.Time
+	status    int
+	method    string
+	path      string
+	route     string
+	proto     string
+	latency   time.Duration
+	bytesOut  int
+	ip        string
+	referer   string
+	userAgent string
+	requestID string
+	err       error
+	headers   []slog.Attr
+}
+
+// AccessLog returns a middleware that writes one access log line per
+// request in the configured format.
+//
+// Errors returned by handlers are passed to the error handler before
+// the line is written, so the logged status is the one sent to the client.
+//
+// Example:
+//
+//	app.Use(mows.AccessLog(mows.AccessLogConfig{
+//	    Format:    mows.AccessLogCombined,
+//	    SkipPaths: []string{"/healthz"},
+//	}))
+func AccessLog(config AccessLogConfig) Middleware {
+	if config.Output == nil {
+		config.Output = os.Stdout
+	}
+	if len(config.RedactHeaders) == 0 {
+		config.RedactHeaders = DefaultRedactedHeaders
+	}
+
+	var logger *slog.Logger
+	switch config.Format {
+	case AccessLogJSON:
+		logger = slog.New(slog.NewJSONHandler(config.Output, nil))
+	case AccessLogLogfmt:
+		logger = slog.New(slog.NewTextHandler(config.Output, nil))
+	}
+
+	// serializes writes of the plain text formats
+	var mu sync.Mutex
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			if slices.Contains(config.SkipPaths, c.Request.URL.Path) ||
+				(config.Skip != nil && config.Skip(c)) {
+				return next(c)
+			}
+
+			start := time.Now()
+
+			err := next(c)
+			if err != nil {
+				c.handleError(err)
+			}
+
+			entry := accessLogEntry{
+				time:      start,
+				status:    c.Writer.status,
+				method:    c.Request.Method,
+				path:      c.Request.URL.RequestURI(),
+				route:     c.Route(),
+				proto:     c.Request.Proto,
+				latency:   time.Since(start),
+				bytesOut:  c.Writer.size,
+				ip:        c.ClientIP(),
+				referer:   c.Request.Referer(),
+				userAgent: c.Request.UserAgent(),
+				requestID: c.RequestID(),
+				err:       err,
+			}
+			for _, name := range config.Headers {
+				if value := c.Request.Header.Get(name); value != "" {
+					entry.headers = append(entry.headers, slog.String(name, redactHeader(name, value, config.RedactHeaders)))
+				}
+			}
+
+			switch config.Format {
+			case AccessLogCommon, AccessLogCombined:
+				line := entry.common(config.Format == AccessLogCombined)
+				mu.Lock()
+				io.WriteString(config.Output, line)
+				mu.Unlock()
+			default:
+				logger.LogAttrs(context.Background(), slog.LevelInfo, "request", entry.attrs()...)
+			}
+
+			return err
+		}
+	}
+}
+
+// attrs returns the entry as structured log attributes.
+func (e accessLogEntry) attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.Int("status", e.status),
+		slog.String("method", e.method),
+		slog.String("path", e.path),
+		slog.String("route", e.route),
+		slog.String("proto", e.proto),
+		slog.Duration("latency", e.latency),
+		slog.Int("bytes_out", e.bytesOut),
+		slog.String("ip", e.ip),
+		slog.String("user_agent", e.userAgent),
+	}
+	if e.requestID != "" {
+		attrs = append(attrs, slog.String("request_id", e.requestID))
+	}
+	if e.err != nil {
+		attrs = append(attrs, slog.String("error", e.err.Error()))
+	}
+	if len(e.headers) > 0 {
+		attrs = append(attrs, slog.Attr{Key: "headers", Value: slog.GroupValue(e.headers...)})
+	}
+	return attrs
+}
+
+// common formats the entry in Apache Common or Combined Log Format.
+func (e accessLogEntry) common(combined bool) string {
+	size := "-"
+	if e.bytesOut > 0 {
+		size = fmt.Sprint(e.bytesOut)
+	}
+
+	line := fmt.Sprintf("%s - - [%s] \"%s %s %s\" %d %s",
+		e.ip,
+		e.time.Format("02/Jan/2006:15:04:05 -0700"),
+		e.method,
+		e.path,
+		e.proto,
+		e.status,
+		size,
+	)
+
+	if combined {
+		line += fmt.Sprintf(" %q %q", orDash(e.referer), orDash(e.userAgent))
+	}
+
+	return line + "\n"
+}
+
+// redactHeader hides the value of sensitive headers.
+func redactHeader(name, value string, redacted []string) string {
+	for _, r := range redacted {
+		if strings.EqualFold(name, r) {
+			return "[REDACTED]"
+		}
+	}
+	return value
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
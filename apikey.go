@@ -0,0 +1,313 @@
+package mows
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// APIKeyKey is the Context store key holding the *APIKeyRecord of an
+// authenticated API key.
+const APIKeyKey = "api_key"
+
+// ScopesKey is the Context store key holding the granted scopes as a
+// []string, checked by RequireScopes.
+const ScopesKey = "scopes"
+
+// APIKeyRecord describes a stored API key. The key itself is never
+// stored, only its hash (see HashAPIKey).
+type APIKeyRecord struct {
+	// ID identifies the key, e.g. for logs and revocation.
+	ID string `json:"id"`
+
+	// Name is a human readable label, such as the owning client.
+	Name string `json:"name,omitempty"`
+
+	// Hash is the HashAPIKey hash of the key.
+	Hash string `json:"hash"`
+
+	// Scopes lists the permissions granted to the key.
+	Scopes []string `json:"scopes,omitempty"`
+
+	// ExpiresAt, when not zero, is the time the key stops working.
+	ExpiresAt time.Time `json:"expires_at,omitzero"`
+}
+
+// KeyStore looks up API keys by hash.
+type KeyStore interface {
+	// Lookup returns the record for a key hash, or nil if no key has
+	// that hash.
+	Lookup(ctx context.Context, hash string) (*APIKeyRecord, error)
+}
+
+// APIKeyConfig defines the configuration for the APIKey middleware.
+type APIKeyConfig struct {
+	// Store holds the known keys. It is required.
+	Store KeyStore
+
+	// Header is the request header holding the key.
+	// Defaults to "X-API-Key".
+	Header string
+
+	// Query, when set, is a query parameter checked after Header.
+	Query string
+}
+
+// APIKey returns a middleware that authenticates machine clients with
+// an API key.
+//
+// The key is read from Header or Query, hashed, and looked up in Store.
+// Unknown and expired keys are rejected with 401 Unauthorized. For valid
+// keys the record is stored under APIKeyKey and PrincipalKey, and its
+// scopes under ScopesKey for RequireScopes.
+//
+// Example:
+//
+//	keys, _ := mows.NewFileKeyStore("/etc/myapp/api-keys.json")
+//
+//	api := app.Group("/api", mows.APIKey(mows.APIKeyConfig{Store: keys}))
+//	api.POST("/orders", mows.RequireScopes("orders:write"), createOrder)
+func APIKey(config APIKeyConfig) Middleware {
+	if config.Store == nil {
+		panic("mows: api key requires a key store")
+	}
+	if config.Header == "" {
+		config.Header = "X-API-Key"
+	}
+	lookup := newTokenLookup(config.Header, "-", config.Query, "")
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			key := lookup.token(c)
+			if key == "" {
+				return NewHTTPError(http.StatusUnauthorized, "")
+			}
+
+			record, err := config.Store.Lookup(c.Request.Context(), HashAPIKey(key))
+			if err != nil {
+				return err
+			}
+			if record == nil || (!record.ExpiresAt.IsZero() && time.Now().After(record.ExpiresAt)) {
+				return NewHTTPError(http.StatusUnauthorized, "")
+			}
+
+			c.Set(APIKeyKey, record)
+			c.Set(PrincipalKey, record)
+			c.Set(ScopesKey, record.Scopes)
+			return next(c)
+		}
+	}
+}
+
+// Scopes returns the scopes granted to the request, as stored under
+// ScopesKey by APIKey or another authentication middleware.
+func (c *Context) Scopes() []string {
+	scopes, _ := c.keys[ScopesKey].([]string)
+	return scopes
+}
+
+// RequireScopes returns a route handler that rejects requests missing
+// any of the given scopes with 403 Forbidden.
+//
+// Use it before the route's handler:
+//
+//	api.POST("/orders", mows.RequireScopes("orders:write"), createOrder)
+func RequireScopes(scopes ...string) HandlerFunc {
+	return func(c *Context) error {
+		granted := c.Scopes()
+		for _, scope := range scopes {
+			if !slices.Contains(granted, scope) {
+				return NewHTTPError(http.StatusForbidden, "missing scope "+scope)
+			}
+		}
+		return nil
+	}
+}
+
+// HashAPIKey returns the hex encoded SHA-256 hash of key.
+//
+// API keys are long random strings, so a fast hash is sufficient;
+// it lets stores look keys up by hash without keeping them in plain text.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// base62 is the alphabet of generated API keys.
+const base62 = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// GenerateAPIKey returns a new random key with the given prefix, and
+// its hash for storing in a KeyStore.
+//
+// The key is the prefix, an underscore and 32 base62 characters
+// (about 190 bits of randomness). Prefixes such as "mk_live" make keys
+// easy to recognize, e.g. by secret scanners.
+//
+// Example:
+//
+//	key, hash, _ := mows.GenerateAPIKey("mk_live")
+//	// key:  mk_live_7Q2c0Jm...
+func GenerateAPIKey(prefix string) (key, hash string, err error) {
+	const length = 32
+
+	buf := make([]byte, 0, len(prefix)+1+length)
+	if prefix != "" {
+		buf = append(buf, prefix...)
+		buf = append(buf, '_')
+	}
+
+	random := make([]byte, 64)
+	for n := 0; n < length; {
+		if _, err := rand.Read(random); err != nil {
+			return "", "", err
+		}
+		for _, b := range random {
+			// reject values that would bias the distribution
+			if b >= 248 {
+				continue
+			}
+			buf = append(buf, base62[b%62])
+			if n++; n == length {
+				break
+			}
+		}
+	}
+
+	key = string(buf)
+	return key, HashAPIKey(key), nil
+}
+
+// MemoryKeyStore is a KeyStore held in memory.
+type MemoryKeyStore struct {
+	mu      sync.RWMutex
+	records map[string]*APIKeyRecord
+}
+
+// NewMemoryKeyStore creates a MemoryKeyStore holding records.
+func NewMemoryKeyStore(records ...APIKeyRecord) *MemoryKeyStore {
+	s := &MemoryKeyStore{records: make(map[string]*APIKeyRecord)}
+	for _, record := range records {
+		s.Add(record)
+	}
+	return s
+}
+
+// Add stores a record, replacing any record with the same hash.
+func (s *MemoryKeyStore) Add(record APIKeyRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[record.Hash] = &record
+}
+
+// Remove deletes the record with the given ID.
+func (s *MemoryKeyStore) Remove(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for hash, record := range s.records {
+		if record.ID == id {
+			delete(s.records, hash)
+		}
+	}
+}
+
+// Lookup implements KeyStore.
+func (s *MemoryKeyStore) Lookup(_ context.Context, hash string) (*APIKeyRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.records[hash], nil
+}
+
+// FileKeyStore is a KeyStore backed by a JSON file holding an array of
+// APIKeyRecord.
+//
+// The file is reloaded when its modification time changes, checked at
+// most once per second, so keys can be added or revoked without a restart.
+// Deleting the file, or making it unreadable, revokes every key.
+//
+// Example file:
+//
+//	[
+//	  {"id": "billing", "hash": "9f86d0...", "scopes": ["orders:read"]}
+//	]
+type FileKeyStore struct {
+	path string
+
+	mu        sync.Mutex
+	store     *MemoryKeyStore
+	modTime   time.Time
+	lastCheck time.Time
+}
+
+// NewFileKeyStore loads the key file at path.
+func NewFileKeyStore(path string) (*FileKeyStore, error) {
+	s := &FileKeyStore{path: path}
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Reload reads the key file now.
+//
+// If the file is missing or unreadable, all keys are revoked. If its
+// content is invalid, the previously loaded keys are kept.
+func (s *FileKeyStore) Reload() error {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		s.clear()
+		return err
+	}
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		s.clear()
+		return err
+	}
+
+	var records []APIKeyRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.store = NewMemoryKeyStore(records...)
+	s.modTime = info.ModTime()
+	return nil
+}
+
+// clear revokes all keys until the file can be read again.
+func (s *FileKeyStore) clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.store = NewMemoryKeyStore()
+	s.modTime = time.Time{}
+}
+
+// Lookup implements KeyStore.
+func (s *FileKeyStore) Lookup(ctx context.Context, hash string) (*APIKeyRecord, error) {
+	s.mu.Lock()
+	check := time.Since(s.lastCheck) >= time.Second
+	if check {
+		s.lastCheck = time.Now()
+	}
+	modTime := s.modTime
+	s.mu.Unlock()
+
+	if check {
+		if info, err := os.Stat(s.path); err != nil || !info.ModTime().Equal(modTime) {
+			s.Reload()
+		}
+	}
+
+	s.mu.Lock()
+	store := s.store
+	s.mu.Unlock()
+	return store.Lookup(ctx, hash)
+}
@@ -0,0 +1,356 @@
+package mows
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// PrincipalKey is the Context store key holding the authenticated
+// principal set by the authentication middleware.
+const PrincipalKey = "principal"
+
+// Principal returns the principal stored by BasicAuth, BearerAuth or
+// another authentication middleware, or nil if the request is not
+// authenticated.
+//
+// Example:
+//
+//	user := c.Principal().(*User)
+func (c *Context) Principal() any {
+	return c.keys[PrincipalKey]
+}
+
+// BasicAuthValidator checks a username and password.
+//
+// It returns the principal to store in the Context, or nil if the
+// credentials are invalid. A non-nil error is passed to the error
+// handler, so lookup failures are not reported as 401.
+type BasicAuthValidator func(c *Context, username, password string) (any, error)
+
+// BasicAuthConfig defines the configuration for the BasicAuth middleware.
+type BasicAuthConfig struct {
+	// Validator checks the credentials. It is required.
+	Validator BasicAuthValidator
+
+	// Realm is sent in the WWW-Authenticate header.
+	// Defaults to "Restricted".
+	Realm string
+}
+
+// BasicAuth returns a middleware that authenticates requests with
+// HTTP Basic authentication.
+//
+// See BasicAuthWithConfig for details.
+//
+// Example:
+//
+//	admin := app.Group("/admin", mows.BasicAuth(mows.BasicAuthUsers(map[string]string{
+//	    "alice": "$argon2id$v=19$m=19456,t=2,p=1$...",
+//	})))
+func BasicAuth(validator BasicAuthValidator) Middleware {
+	return BasicAuthWithConfig(BasicAuthConfig{Validator: validator})
+}
+
+// BasicAuthWithConfig returns a BasicAuth middleware with custom settings.
+//
+// Requests without valid credentials are rejected with 401 Unauthorized
+// and a WWW-Authenticate challenge for the realm. When the validator
+// accepts the credentials, its principal is stored under PrincipalKey
+// and available from c.Principal().
+func BasicAuthWithConfig(config BasicAuthConfig) Middleware {
+	if config.Validator == nil {
+		panic("mows: basic auth requires a validator")
+	}
+	if config.Realm == "" {
+		config.Realm = "Restricted"
+	}
+	challenge := fmt.Sprintf("Basic realm=%q, charset=\"UTF-8\"", config.Realm)
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			username, password, ok := c.Request.BasicAuth()
+			if !ok {
+				c.Writer.Header().Set("WWW-Authenticate", challenge)
+				return NewHTTPError(http.StatusUnauthorized, "")
+			}
+
+			principal, err := config.Validator(c, username, password)
+			if err != nil {
+				return err
+			}
+			if principal == nil {
+				c.Writer.Header().Set("WWW-Authenticate", challenge)
+				return NewHTTPError(http.StatusUnauthorized, "")
+			}
+
+			c.Set(PrincipalKey, principal)
+			return next(c)
+		}
+	}
+}
+
+// BasicAuthUsers returns a BasicAuthValidator for a fixed set of users,
+// mapping usernames to password hashes. The principal is the username.
+//
+// Hashes must be bcrypt or argon2id (see HashPassword); users with any
+// other value cannot log in. Unknown usernames are checked against a
+// dummy hash so they cannot be told apart by response time.
+func BasicAuthUsers(users map[string]string) BasicAuthValidator {
+	return BasicAuthUsersWithVerifier(users, VerifyPassword)
+}
+
+// BasicAuthUsersWithVerifier returns a BasicAuthUsers validator that
+// checks passwords with verify, such as VerifyPlainPassword.
+func BasicAuthUsersWithVerifier(users map[string]string, verify PasswordVerifier) BasicAuthValidator {
+	return func(c *Context, username, password string) (any, error) {
+		hash, ok := users[username]
+		if !ok {
+			verify(dummyPasswordHash(), password)
+			return nil, nil
+		}
+		if !verify(hash, password) {
+			return nil, nil
+		}
+		return username, nil
+	}
+}
+
+// BearerValidator resolves a bearer token to a principal.
+//
+// It returns nil if the token is invalid. A non-nil error is passed to
+// the error handler.
+type BearerValidator func(c *Context, token string) (any, error)
+
+// BearerAuthConfig defines the configuration for the BearerAuth middleware.
+type BearerAuthConfig struct {
+	// Validator resolves tokens. It is required.
+	Validator BearerValidator
+
+	// Realm is sent in the WWW-Authenticate header.
+	// Defaults to "Restricted".
+	Realm string
+
+	// Header is the request header holding the token.
+	// Defaults to "Authorization".
+	Header string
+
+	// Scheme is the prefix expected before the token in Header.
+	// Defaults to "Bearer". Set it to "-" for headers that carry
+	// the bare token.
+	Scheme string
+
+	// Query, when set, is a query parameter checked after Header.
+	Query string
+
+	// Cookie, when set, is a cookie checked after Query.
+	Cookie string
+}
+
+// BearerAuth returns a middleware that authenticates requests with a
+// bearer token read from the Authorization header.
+//
+// See BearerAuthWithConfig for details.
+//
+// Example:
+//
+//	api := app.Group("/api", mows.BearerAuth(func(c *mows.Context, token string) (any, error) {
+//	    return sessions.Lookup(c.Request.Context(), token)
+//	}))
+func BearerAuth(validator BearerValidator) Middleware {
+	return BearerAuthWithConfig(BearerAuthConfig{Validator: validator})
+}
+
+// BearerAuthWithConfig returns a BearerAuth middleware with custom settings.
+//
+// The token is taken from the first of Header, Query and Cookie that is
+// present. Requests without a token, or whose token the validator
+// rejects, get 401 Unauthorized with an RFC 6750 WWW-Authenticate
+// challenge. The resolved principal is stored under PrincipalKey and
+// available from c.Principal().
+func BearerAuthWithConfig(config BearerAuthConfig) Middleware {
+	if config.Validator == nil {
+		panic("mows: bearer auth requires a validator")
+	}
+	if config.Realm == "" {
+		config.Realm = "Restricted"
+	}
+	lookup := newTokenLookup(config.Header, config.Scheme, config.Query, config.Cookie)
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			token := lookup.token(c)
+			if token == "" {
+				c.Writer.Header().Set("WWW-Authenticate", fmt.Sprintf("Bearer realm=%q", config.Realm))
+				return NewHTTPError(http.StatusUnauthorized, "")
+			}
+
+			principal, err := config.Validator(c, token)
+			if err != nil {
+				return err
+			}
+			if principal == nil {
+				c.Writer.Header().Set("WWW-Authenticate", fmt.Sprintf("Bearer realm=%q, error=\"invalid_token\"", config.Realm))
+				return NewHTTPError(http.StatusUnauthorized, "")
+			}
+
+			c.Set(PrincipalKey, principal)
+			return next(c)
+		}
+	}
+}
+
+// tokenLookup reads a credential from a header, query parameter or cookie.
+type tokenLookup struct {
+	header string
+	scheme string
+	query  string
+	cookie string
+}
+
+// newTokenLookup applies the defaults shared by token based middleware.
+func newTokenLookup(header, scheme, query, cookie string) tokenLookup {
+	if header == "" {
+		header = "Authorization"
+	}
+	switch scheme {
+	case "":
+		scheme = "Bearer"
+	case "-":
+		scheme = ""
+	}
+	return tokenLookup{header: header, scheme: scheme, query: query, cookie: cookie}
+}
+
+// token returns the first credential found, or an empty string.
+func (l tokenLookup) token(c *Context) string {
+	if value := c.Request.Header.Get(l.header); value != "" {
+		if l.scheme == "" {
+			return strings.TrimSpace(value)
+		}
+		scheme, token, ok := strings.Cut(value, " ")
+		if ok && strings.EqualFold(scheme, l.scheme) {
+			return strings.TrimSpace(token)
+		}
+	}
+
+	if l.query != "" {
+		if token := c.Request.URL.Query().Get(l.query); token != "" {
+			return token
+		}
+	}
+
+	if l.cookie != "" {
+		if cookie, err := c.Request.Cookie(l.cookie); err == nil && cookie.Value != "" {
+			return cookie.Value
+		}
+	}
+
+	return ""
+}
+
+// argon2id parameters used by HashPassword, following the OWASP
+// password storage recommendations.
+const (
+	argon2Memory  = 19 * 1024
+	argon2Time    = 2
+	argon2Threads = 1
+	argon2KeyLen  = 32
+	argon2SaltLen = 16
+)
+
+// HashPassword hashes a password with argon2id and returns it in the
+// PHC string format, e.g. "$argon2id$v=19$m=19456,t=2,p=1$<salt>$<hash>".
+func HashPassword(password string) (string, error) {
+	salt := make([]byte, argon2SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, argon2Time, argon2Memory, argon2Threads, argon2KeyLen)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		argon2Memory,
+		argon2Time,
+		argon2Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// PasswordVerifier reports whether password matches a stored hash.
+type PasswordVerifier func(hash, password string) bool
+
+// VerifyPassword reports whether password matches hash.
+//
+// It accepts bcrypt hashes ("$2a$", "$2b$", "$2y$") and argon2id hashes
+// in PHC format. Any other hash, including an empty one, never matches.
+func VerifyPassword(hash, password string) bool {
+	switch {
+	case strings.HasPrefix(hash, "$2a$"), strings.HasPrefix(hash, "$2b$"), strings.HasPrefix(hash, "$2y$"):
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+	case strings.HasPrefix(hash, "$argon2id$"):
+		return verifyArgon2id(hash, password)
+	default:
+		return false
+	}
+}
+
+// VerifyPlainPassword reports whether password equals the plain text
+// stored password, in constant time. An empty stored password never
+// matches.
+//
+// Prefer hashed passwords; use this only for fixtures and development:
+//
+//	mows.BasicAuthUsersWithVerifier(users, mows.VerifyPlainPassword)
+func VerifyPlainPassword(stored, password string) bool {
+	return stored != "" && subtle.ConstantTimeCompare([]byte(stored), []byte(password)) == 1
+}
+
+// verifyArgon2id checks password against an argon2id PHC string.
+func verifyArgon2id(hash, password string) bool {
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return false
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false
+	}
+
+	var memory, time uint32
+	var threads uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads); err != nil ||
+		memory == 0 || time == 0 || threads == 0 {
+		return false
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false
+	}
+	want, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(want) == 0 {
+		return false
+	}
+
+	got := argon2.IDKey([]byte(password), salt, time, memory, threads, uint32(len(want)))
+	return subtle.ConstantTimeCompare(got, want) == 1
+}
+
+// dummyPasswordHash is verified for unknown users so that a missing
+// account costs as much time as a wrong password.
+var dummyPasswordHash = sync.OnceValue(func() string {
+	hash, _ := HashPassword("mows-dummy-password")
+	return hash
+})
@@ -0,0 +1,422 @@
+package mows
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+// Policy decides whether the current principal satisfies a requirement.
+type Policy interface {
+	// Authorize returns nil if the requirement is satisfied, an
+	// *AuthorizationError if it is not, or another error if the
+	// decision could not be made.
+	Authorize(c *Context, requirement string) error
+}
+
+// PolicyFunc adapts a function to the Policy interface.
+type PolicyFunc func(c *Context, requirement string) error
+
+// Authorize implements Policy.
+func (f PolicyFunc) Authorize(c *Context, requirement string) error {
+	return f(c, requirement)
+}
+
+// AuthorizationError is returned when a principal does not satisfy a
+// requirement. The Authorize middleware wraps it in a 403 HTTPError,
+// so error handlers can inspect it with errors.As.
+type AuthorizationError struct {
+	// Requirement is the requirement that was not satisfied.
+	Requirement string
+
+	// Reason explains the denial, for logs.
+	Reason string
+}
+
+// Error implements the error interface.
+func (e *AuthorizationError) Error() string {
+	if e.Reason == "" {
+		return "forbidden: requires " + e.Requirement
+	}
+	return "forbidden: requires " + e.Requirement + ": " + e.Reason
+}
+
+// Authorize returns a middleware that checks the requirements of the
+// matched route (see RouterGroup.Require) against policy.
+//
+// It must run after the authentication middleware. Requests without a
+// principal get 401 Unauthorized; requests whose principal fails a
+// requirement get 403 Forbidden, with the *AuthorizationError passed
+// to the error handler. Routes without requirements pass through.
+//
+// Example:
+//
+//	rbac := mows.NewRBAC().
+//	    Grant("viewer", "orders:read").
+//	    Grant("editor", "orders:write").
+//	    Inherit("editor", "viewer")
+//
+//	api := app.Group("/api", mows.JWT(jwtConfig), mows.Authorize(rbac))
+//	api.Group("/orders").Require("orders:write").POST("", createOrder)
+func Authorize(policy Policy) Middleware {
+	if policy == nil {
+		panic("mows: authorize requires a policy")
+	}
+
+	m := Middleware(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			var requirements []string
+			if c.meta != nil {
+				requirements = c.meta.Requirements
+			}
+
+			if len(requirements) > 0 && c.Principal() == nil {
+				return NewHTTPError(http.StatusUnauthorized, "")
+			}
+
+			for _, requirement := range requirements {
+				if err := policy.Authorize(c, requirement); err != nil {
+					var authErr *AuthorizationError
+					if errors.As(err, &authErr) {
+						return &HTTPError{Code: http.StatusForbidden, Message: http.StatusText(http.StatusForbidden), Err: err}
+					}
+					return err
+				}
+			}
+
+			c.authorized = true
+			return next(c)
+		}
+	})
+
+	authorizePolicies.Store(closureKey(m), policy)
+	return m
+}
+
+// requireAuthorized guards a route with requirements against a missing
+// Authorize middleware.
+func requireAuthorized(next HandlerFunc) HandlerFunc {
+	return func(c *Context) error {
+		if !c.authorized {
+			c.Logger().Error("route has requirements but no Authorize middleware checked them",
+				"requirements", c.meta.Requirements,
+			)
+			return NewHTTPError(http.StatusInternalServerError, "")
+		}
+		return next(c)
+	}
+}
+
+// AnyPolicy returns a Policy that is satisfied if any of policies is.
+//
+// Errors other than *AuthorizationError stop the evaluation.
+func AnyPolicy(policies ...Policy) Policy {
+	return PolicyFunc(func(c *Context, requirement string) error {
+		var denied error = &AuthorizationError{Requirement: requirement}
+		for _, policy := range policies {
+			err := policy.Authorize(c, requirement)
+			if err == nil {
+				return nil
+			}
+			var authErr *AuthorizationError
+			if !errors.As(err, &authErr) {
+				return err
+			}
+			denied = err
+		}
+		return denied
+	})
+}
+
+// RoleHolder is implemented by principals that carry roles.
+type RoleHolder interface {
+	Roles() []string
+}
+
+// RBAC is a role based access control Policy.
+//
+// Roles are granted permissions and may inherit the permissions of
+// other roles. A requirement is satisfied if the principal has a role
+// of that name, directly or by inheritance, or a permission matching
+// it. Permissions ending in "*" match by prefix, so "orders:*" grants
+// "orders:read" and "*" grants everything.
+type RBAC struct {
+	// Roles returns the roles of the request's principal. Defaults to
+	// calling Roles on principals that implement RoleHolder.
+	Roles func(c *Context) []string
+
+	mu          sync.RWMutex
+	permissions map[string][]string
+	parents     map[string][]string
+}
+
+// NewRBAC creates an empty RBAC policy.
+func NewRBAC() *RBAC {
+	return &RBAC{
+		permissions: make(map[string][]string),
+		parents:     make(map[string][]string),
+	}
+}
+
+// Grant gives permissions to role.
+func (r *RBAC) Grant(role string, permissions ...string) *RBAC {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.permissions[role] = append(r.permissions[role], permissions...)
+	return r
+}
+
+// Inherit makes role inherit the permissions of parents.
+func (r *RBAC) Inherit(role string, parents ...string) *RBAC {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.parents[role] = append(r.parents[role], parents...)
+	return r
+}
+
+// Allowed reports whether any of roles satisfies requirement.
+func (r *RBAC) Allowed(roles []string, requirement string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	queue := slices.Clone(roles)
+	for len(queue) > 0 {
+		role := queue[0]
+		queue = queue[1:]
+		if seen[role] {
+			continue
+		}
+		seen[role] = true
+
+		if role == requirement {
+			return true
+		}
+		for _, permission := range r.permissions[role] {
+			if matchPermission(permission, requirement) {
+				return true
+			}
+		}
+		queue = append(queue, r.parents[role]...)
+	}
+
+	return false
+}
+
+// Authorize implements Policy.
+func (r *RBAC) Authorize(c *Context, requirement string) error {
+	var roles []string
+	if r.Roles != nil {
+		roles = r.Roles(c)
+	} else if holder, ok := c.Principal().(RoleHolder); ok {
+		roles = holder.Roles()
+	}
+
+	if !r.Allowed(roles, requirement) {
+		return &AuthorizationError{
+			Requirement: requirement,
+			Reason:      fmt.Sprintf("roles %v lack it", roles),
+		}
+	}
+	return nil
+}
+
+// matchPermission reports whether a granted permission covers requirement.
+func matchPermission(granted, requirement string) bool {
+	if prefix, ok := strings.CutSuffix(granted, "*"); ok {
+		return strings.HasPrefix(requirement, prefix)
+	}
+	return granted == requirement
+}
+
+// Attributes describe a request for ABAC rules.
+type Attributes struct {
+	Principal any
+	Params    map[string]string
+	Method    string
+	Route     string
+	Path      string
+	ClientIP  string
+	Header    http.Header
+	Query     url.Values
+}
+
+// ABACRule decides a requirement from request attributes.
+type ABACRule func(a Attributes) bool
+
+// ABAC is an attribute based access control Policy.
+//
+// Each requirement has a list of rules; it is satisfied if any of them
+// returns true. Requirements without rules are denied.
+//
+// Example:
+//
+//	abac := mows.NewABAC().Rule("orders:edit", func(a mows.Attributes) bool {
+//	    return a.Principal.(*User).ID == a.Params["user_id"]
+//	})
+type ABAC struct {
+	mu    sync.RWMutex
+	rules map[string][]ABACRule
+}
+
+// NewABAC creates an ABAC policy without rules.
+func NewABAC() *ABAC {
+	return &ABAC{rules: make(map[string][]ABACRule)}
+}
+
+// Rule adds a rule for requirement.
+func (p *ABAC) Rule(requirement string, rule ABACRule) *ABAC {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.rules[requirement] = append(p.rules[requirement], rule)
+	return p
+}
+
+// Authorize implements Policy.
+func (p *ABAC) Authorize(c *Context, requirement string) error {
+	p.mu.RLock()
+	rules := p.rules[requirement]
+	p.mu.RUnlock()
+
+	if len(rules) == 0 {
+		return &AuthorizationError{Requirement: requirement, Reason: "no rule defined"}
+	}
+
+	attrs := Attributes{
+		Principal: c.Principal(),
+		Params:    c.Params,
+		Method:    c.Request.Method,
+		Route:     c.Route(),
+		Path:      c.Request.URL.Path,
+		ClientIP:  c.ClientIP(),
+		Header:    c.Request.Header,
+		Query:     c.Request.URL.Query(),
+	}
+	for _, rule := range rules {
+		if rule(attrs) {
+			return nil
+		}
+	}
+
+	return &AuthorizationError{Requirement: requirement, Reason: "no rule allows it"}
+}
+
+// guardMiddlewares lists the middleware that authenticate or authorize
+// requests, as named by middlewareName.
+var guardMiddlewares = []string{
+	"mows.APIKey",
+	"mows.Authorize",
+	"mows.BasicAuth",
+	"mows.BearerAuth",
+	"mows.ClientCertAuth",
+	"mows.JWT",
+}
+
+// RouteGuard describes how a route is protected.
+type RouteGuard struct {
+	Method  string
+	Pattern string
+
+	// Requirements are the route's requirements (see RouterGroup.Require).
+	Requirements []string
+
+	// Guards names the authentication and authorization middleware that
+	// run for the route, global ones first, e.g. "mows.JWT" and
+	// "mows.Authorize".
+	Guards []string
+
+	// Policies are the policies of the Authorize middleware that run for
+	// the route, in order. Pointer policies such as *RBAC can be compared
+	// with ==.
+	Policies []Policy
+
+	// MissingAuthorize reports a route with Requirements but no
+	// Authorize middleware. Such routes fail with 500 Internal Server
+	// Error at runtime.
+	MissingAuthorize bool
+}
+
+// RouteGuards reports, for every route, its requirements and the
+// middleware and policies guarding it. Routes with empty Guards are
+// public.
+//
+// Example:
+//
+//	for _, g := range app.RouteGuards() {
+//	    if g.MissingAuthorize {
+//	        log.Fatalf("%s %s has requirements but no Authorize", g.Method, g.Pattern)
+//	    }
+//	    if strings.HasPrefix(g.Pattern, "/admin") && !slices.Contains(g.Policies, mows.Policy(adminRBAC)) {
+//	        log.Fatalf("%s %s is not protected by the admin policy", g.Method, g.Pattern)
+//	    }
+//	}
+func (e *Engine) RouteGuards() []RouteGuard {
+	var guards []RouteGuard
+	for _, r := range e.routes() {
+		guard := RouteGuard{
+			Method:       r.Method,
+			Pattern:      r.Pattern,
+			Requirements: r.Meta.Requirements,
+			Policies:     r.policies,
+		}
+		for _, name := range r.Middlewares {
+			if slices.Contains(guardMiddlewares, name) {
+				guard.Guards = append(guard.Guards, name)
+			}
+		}
+		guard.MissingAuthorize = len(guard.Requirements) > 0 && len(guard.Policies) == 0
+		guards = append(guards, guard)
+	}
+	return guards
+}
+
+// authorizePolicies maps the middleware returned by Authorize, by
+// closureKey, to its policy.
+var authorizePolicies sync.Map
+
+// authorizePolicy returns the policy of an Authorize middleware, or nil
+// for other middleware.
+func authorizePolicy(m Middleware) Policy {
+	if policy, ok := authorizePolicies.Load(closureKey(m)); ok {
+		return policy.(Policy)
+	}
+	return nil
+}
+
+// closureKey identifies a function value. Unlike its code pointer, it
+// differs between the closures returned by separate calls to a
+// constructor such as Authorize.
+func closureKey[F ~func(HandlerFunc) HandlerFunc | ~func(*Context) error](fn F) unsafe.Pointer {
+	return *(*unsafe.Pointer)(unsafe.Pointer(&fn))
+}
+
+// middlewareName returns the name of the function that created m,
+// e.g. "mows.JWT" or "main.auth".
+func middlewareName(m Middleware) string {
+	return strings.TrimSuffix(funcName(m), "WithConfig")
+}
+
+// funcName returns the package-qualified name of fn, a function value.
+// Closures are named after their enclosing function.
+func funcName(fn any) string {
+	name := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
+
+	// closures are named after their enclosing function: "pkg.JWT.func1"
+	name = strings.TrimSuffix(name, "-fm")
+	for {
+		i := strings.LastIndex(name, ".func")
+		if i < 0 || strings.Trim(name[i+len(".func"):], "0123456789") != "" {
+			break
+		}
+		name = name[:i]
+	}
+
+	return name[strings.LastIndex(name, "/")+1:]
+}
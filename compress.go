@@ -0,0 +1,378 @@
+package mows
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Encoder creates compressing writers for a single content-coding.
+//
+// Built-in encoders are provided for gzip and deflate. Additional
+// encodings (for example zstd or br) can be plugged in by implementing
+// this interface and listing the encoder in CompressConfig.Encoders.
+type Encoder interface {
+	// Encoding returns the content-coding token, e.g. "gzip".
+	Encoding() string
+
+	// NewWriter returns a compressing writer that writes to w.
+	NewWriter(w io.Writer) (EncoderWriter, error)
+}
+
+// EncoderWriter is a compressing writer that can be reused.
+//
+// gzip.Writer, flate.Writer and most third-party encoders already
+// satisfy this interface.
+type EncoderWriter interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// CompressConfig defines the configuration for the Compress middleware.
+type CompressConfig struct {
+	// MinLength is the minimum response size in bytes before compression
+	// is applied. Defaults to 1024.
+	MinLength int
+
+	// ContentTypes lists the media types eligible for compression.
+	// A trailing "/*" matches every subtype, e.g. "text/*".
+	// Defaults to DefaultCompressContentTypes.
+	ContentTypes []string
+
+	// Encoders lists the supported encodings in order of server
+	// preference. Defaults to gzip and deflate at default level.
+	Encoders []Encoder
+}
+
+// DefaultCompressContentTypes is the list of media types compressed
+// when CompressConfig.ContentTypes is empty.
+var DefaultCompressContentTypes = []string{
+	"text/*",
+	"application/json",
+	"application/javascript",
+	"application/xml",
+	"application/problem+json",
+	"image/svg+xml",
+}
+
+type gzipEncoder struct{ level int }
+
+// GzipEncoder returns an Encoder for the gzip content-coding.
+//
+// level follows the compress/gzip constants; 0 selects the default level.
+func GzipEncoder(level int) Encoder {
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	return gzipEncoder{level: level}
+}
+
+func (g gzipEncoder) Encoding() string { return "gzip" }
+
+func (g gzipEncoder) NewWriter(w io.Writer) (EncoderWriter, error) {
+	return gzip.NewWriterLevel(w, g.level)
+}
+
+type deflateEncoder struct{ level int }
+
+// DeflateEncoder returns an Encoder for the deflate content-coding.
+//
+// The body uses the zlib format, as required for "deflate" by RFC 9110.
+// level follows the compress/zlib constants; 0 selects the default level.
+func DeflateEncoder(level int) Encoder {
+	if level == 0 {
+		level = zlib.DefaultCompression
+	}
+	return deflateEncoder{level: level}
+}
+
+func (d deflateEncoder) Encoding() string { return "deflate" }
+
+func (d deflateEncoder) NewWriter(w io.Writer) (EncoderWriter, error) {
+	return zlib.NewWriterLevel(w, d.level)
+}
+
+// encoderPool pools writers for a single Encoder.
+type encoderPool struct {
+	encoder Encoder
+	pool    sync.Pool
+}
+
+func (p *encoderPool) get(w io.Writer) (EncoderWriter, error) {
+	if ew, ok := p.pool.Get().(EncoderWriter); ok {
+		ew.Reset(w)
+		return ew, nil
+	}
+	return p.encoder.NewWriter(w)
+}
+
+func (p *encoderPool) put(ew EncoderWriter) {
+	ew.Reset(io.Discard)
+	p.pool.Put(ew)
+}
+
+// Compress returns a middleware that compresses responses based on
+// the client's Accept-Encoding header.
+//
+// Responses are compressed only when:
+//
+//   - The client accepts one of the configured encodings
+//   - The body is at least MinLength bytes (or the handler flushes)
+//   - The Content-Type matches one of ContentTypes
+//   - The response is not already encoded and is not a range request
+//
+// Example:
+//
+//	app.Use(mows.Compress(mows.CompressConfig{}))
+func Compress(config CompressConfig) Middleware {
+	if config.MinLength <= 0 {
+		config.MinLength = 1024
+	}
+	if len(config.ContentTypes) == 0 {
+		config.ContentTypes = DefaultCompressContentTypes
+	}
+	if len(config.Encoders) == 0 {
+		config.Encoders = []Encoder{GzipEncoder(0), DeflateEncoder(0)}
+	}
+
+	pools := make([]*encoderPool, len(config.Encoders))
+	for i, enc := range config.Encoders {
+		pools[i] = &encoderPool{encoder: enc}
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			c.Writer.Header().Add("Vary", "Accept-Encoding")
+
+			if c.Request.Header.Get("Range") != "" || c.Request.Method == http.MethodHead {
+				return next(c)
+			}
+
+			pool := negotiateEncoding(c.Request.Header.Get("Accept-Encoding"), pools)
+			if pool == nil {
+				return next(c)
+			}
+
+			original := c.Writer.ResponseWriter
+			cw := &compressWriter{
+				ResponseWriter: original,
+				config:         &config,
+				pool:           pool,
+				status:         http.StatusOK,
+			}
+			c.Writer.ResponseWriter = cw
+
+			defer func() {
+				cw.close()
+				c.Writer.ResponseWriter = original
+			}()
+
+			return next(c)
+		}
+	}
+}
+
+// negotiateEncoding picks the best encoding accepted by the client.
+//
+// Higher q-values win; ties are broken by server preference order.
+// Returns nil when no configured encoding is acceptable.
+func negotiateEncoding(header string, pools []*encoderPool) *encoderPool {
+	if header == "" {
+		return nil
+	}
+
+	accepted := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		accepted[name] = q
+	}
+
+	var best *encoderPool
+	bestQ := 0.0
+	for _, p := range pools {
+		q, ok := accepted[p.encoder.Encoding()]
+		if !ok {
+			q, ok = accepted["*"]
+		}
+		if !ok || q <= bestQ {
+			continue
+		}
+		best, bestQ = p, q
+	}
+
+	return best
+}
+
+// compressWriter buffers the start of a response until it can decide
+// whether to compress, then streams the rest through the encoder.
+type compressWriter struct {
+	http.ResponseWriter
+	config *CompressConfig
+	pool   *encoderPool
+
+	buf         bytes.Buffer
+	status      int
+	wroteHeader bool
+	decided     bool
+	encoder     EncoderWriter
+}
+
+// WriteHeader records the status code; it is sent once the
+// compression decision has been made.
+func (cw *compressWriter) WriteHeader(code int) {
+	if cw.wroteHeader {
+		return
+	}
+	cw.wroteHeader = true
+	cw.status = code
+
+	if code == http.StatusNoContent || code == http.StatusNotModified {
+		cw.decide(false)
+	}
+}
+
+// Write buffers data until MinLength is reached, then compresses or
+// passes it through.
+func (cw *compressWriter) Write(b []byte) (int, error) {
+	cw.wroteHeader = true
+
+	if cw.decided {
+		if cw.encoder != nil {
+			return cw.encoder.Write(b)
+		}
+		return cw.ResponseWriter.Write(b)
+	}
+
+	cw.buf.Write(b)
+	if cw.buf.Len() >= cw.config.MinLength {
+		if err := cw.decide(cw.eligible()); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(b), nil
+}
+
+// Flush commits the compression decision and flushes both the
+// encoder and the underlying writer, so streamed responses keep working.
+// Before anything has been written there is nothing to decide on, so
+// the flush is deferred to the first write.
+func (cw *compressWriter) Flush() {
+	if !cw.decided {
+		if cw.buf.Len() == 0 {
+			return
+		}
+		cw.decide(cw.eligible())
+	}
+	if cw.encoder != nil {
+		cw.encoder.Flush()
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying ResponseWriter.
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// eligible reports whether the buffered response may be compressed.
+func (cw *compressWriter) eligible() bool {
+	h := cw.ResponseWriter.Header()
+	if h.Get("Content-Encoding") != "" {
+		return false
+	}
+
+	// the encoded body can no longer be sniffed, so detect it now
+	contentType := h.Get("Content-Type")
+	if contentType == "" && cw.buf.Len() > 0 {
+		contentType = http.DetectContentType(cw.buf.Bytes())
+		h.Set("Content-Type", contentType)
+	}
+
+	return matchContentType(contentType, cw.config.ContentTypes)
+}
+
+// decide writes the response header and buffered body, either through
+// an encoder or as-is.
+func (cw *compressWriter) decide(compress bool) error {
+	cw.decided = true
+	h := cw.ResponseWriter.Header()
+
+	if compress {
+		enc, err := cw.pool.get(cw.ResponseWriter)
+		if err != nil {
+			return err
+		}
+		cw.encoder = enc
+		h.Set("Content-Encoding", cw.pool.encoder.Encoding())
+		h.Del("Content-Length")
+	}
+
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	if cw.buf.Len() == 0 {
+		return nil
+	}
+
+	var err error
+	if cw.encoder != nil {
+		_, err = cw.encoder.Write(cw.buf.Bytes())
+	} else {
+		_, err = cw.ResponseWriter.Write(cw.buf.Bytes())
+	}
+	cw.buf.Reset()
+	return err
+}
+
+// close finishes the response and returns the encoder to its pool.
+func (cw *compressWriter) close() {
+	if !cw.decided {
+		if !cw.wroteHeader {
+			return
+		}
+		cw.decide(false)
+	}
+
+	if cw.encoder != nil {
+		cw.encoder.Close()
+		cw.pool.put(cw.encoder)
+		cw.encoder = nil
+	}
+}
+
+// matchContentType reports whether contentType matches one of the patterns.
+func matchContentType(contentType string, patterns []string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+
+	for _, p := range patterns {
+		if prefix, ok := strings.CutSuffix(p, "/*"); ok {
+			if strings.HasPrefix(mediaType, prefix+"/") {
+				return true
+			}
+			continue
+		}
+		if mediaType == p {
+			return true
+		}
+	}
+
+	return false
+}
@@ -3,7 +3,9 @@ package mows
 import (
 	"encoding/json"
 	"errors"
+	"html/template"
 	"io"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"strings"
@@ -16,12 +18,22 @@ import (
 //   - Sending responses
 //   - Reading request data
 //   - Accessing path parameters
+//   - Sharing values between middleware and handlers
 type Context struct {
 	Writer  *responseWriter
 	Request *http.Request
 	Params  map[string]string
 	Status  int
 	engine  *Engine
+	keys    map[string]any
+	route   string
+	meta    *RouteMeta
+	logger  *slog.Logger
+
+	templateFuncs template.FuncMap
+
+	errorHandled bool
+	authorized   bool
 }
 
 // NewContext creates a new Context for the incoming HTTP request.
@@ -36,11 +48,80 @@ func NewContext(w *responseWriter, r *http.Request, engine *Engine) *Context {
 	}
 }
 
+// Set stores a value in the Context for the lifetime of the request.
+//
+// It is typically used by middleware to pass data (such as the
+// authenticated user) to handlers.
+func (c *Context) Set(key string, value any) {
+	if c.keys == nil {
+		c.keys = make(map[string]any)
+	}
+	c.keys[key] = value
+}
+
+// Get returns the value stored under key and whether it exists.
+func (c *Context) Get(key string) (any, bool) {
+	value, ok := c.keys[key]
+	return value, ok
+}
+
+// Protocol returns the negotiated protocol of the request using ALPN
+// identifiers: "h2" for HTTP/2 over TLS, "h2c" for cleartext HTTP/2,
+// and "http/1.1" or "http/1.0" otherwise.
+func (c *Context) Protocol() string {
+	if c.Request.ProtoMajor == 2 {
+		if c.Request.TLS == nil {
+			return "h2c"
+		}
+		return "h2"
+	}
+	return strings.ToLower(c.Request.Proto)
+}
+
+// Route returns the pattern of the matched route, e.g. "/users/:id".
+//
+// It returns an empty string if no route matched.
+func (c *Context) Route() string {
+	return c.route
+}
+
+// Logger returns a logger scoped to the current request.
+//
+// It is derived from the engine logger (see Engine.SetLogger) and
+// includes the request method, matched route pattern and, when the
+// RequestID middleware is used, the request ID.
+func (c *Context) Logger() *slog.Logger {
+	if c.logger == nil {
+		logger := c.engine.logger.With(
+			"method", c.Request.Method,
+			"route", c.route,
+		)
+		if id := c.RequestID(); id != "" {
+			logger = logger.With("request_id", id)
+		}
+		c.logger = logger
+	}
+	return c.logger
+}
+
+// handleError passes err to the engine's error handler.
+//
+// It runs at most once per request, so middleware can handle errors
+// early (for example to log the final status) without the engine
+// writing a second response.
+func (c *Context) handleError(err error) {
+	if c.errorHandled {
+		return
+	}
+	c.errorHandled = true
+	c.engine.errorHandler(c, err)
+}
+
 // JSON sends a JSON response with the provided status code.
 func (c *Context) JSON(code int, v any) error {
 	c.Writer.Header().Set("Content-Type", "application/json")
 	c.Writer.WriteHeader(code)
-	return json.NewEncoder(c.Writer.ResponseWriter).Encode(v)
+	return json.NewEncoder(c.Writer).Encode(v)
 }
 
 // String sends a plain text response.
@@ -199,5 +280,5 @@ func (c *Context) HTML(code int, name string, data any) error {
 	c.Writer.Header().Set("Content-Type", "text/html; charset=utf-8")
 	c.Writer.WriteHeader(code)
 
-	return c.engine.templates.tmpl.ExecuteTemplate(c.Writer, name, data)
+	return c.engine.templates.execute(c.Writer, name, data, c.templateFuncs)
 }
@@ -0,0 +1,351 @@
+package mows
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CSRFKey is the Context store key holding the CSRF token of the request.
+const CSRFKey = "csrf_token"
+
+// Errors wrapped in the 403 HTTPError returned by the CSRF middleware.
+var (
+	ErrCSRFToken  = errors.New("csrf token is missing or invalid")
+	ErrCSRFOrigin = errors.New("csrf origin check failed")
+)
+
+// CSRFMode selects how the CSRF middleware stores tokens.
+type CSRFMode int
+
+const (
+	// CSRFDoubleSubmit keeps the token in a cookie that requests must
+	// echo in a header or form field. No server-side state is needed.
+	CSRFDoubleSubmit CSRFMode = iota
+
+	// CSRFSynchronizer keeps one token per session in a CSRFStore.
+	// It requires CSRFConfig.Session.
+	CSRFSynchronizer
+)
+
+// CSRFStore holds synchronizer tokens by session ID.
+type CSRFStore interface {
+	// Get returns the token of a session, or an empty string.
+	Get(ctx context.Context, session string) (string, error)
+
+	// Set stores the token of a session.
+	Set(ctx context.Context, session, token string) error
+}
+
+// CSRFConfig defines the configuration for the CSRF middleware.
+type CSRFConfig struct {
+	// Mode selects double-submit cookies or synchronizer tokens.
+	// Defaults to CSRFDoubleSubmit.
+	Mode CSRFMode
+
+	// Session returns the session ID of the request, or an empty string
+	// when there is no session. It is required for CSRFSynchronizer.
+	// With CSRFDoubleSubmit and a Secret, tokens are signed for the
+	// session so a token planted by a sibling subdomain is rejected.
+	Session func(c *Context) string
+
+	// Secret signs double-submit tokens bound to a session.
+	Secret []byte
+
+	// Store holds synchronizer tokens. Defaults to an in-memory store.
+	Store CSRFStore
+
+	// Header is the request header carrying the token.
+	// Defaults to "X-CSRF-Token".
+	Header string
+
+	// FormField is the form field carrying the token.
+	// Defaults to "csrf_token".
+	FormField string
+
+	// CookieName is the double-submit cookie. Defaults to "_csrf".
+	CookieName string
+
+	// CookiePath defaults to "/".
+	CookiePath string
+
+	// CookieDomain is empty by default, limiting the cookie to the host.
+	CookieDomain string
+
+	// CookieMaxAge defaults to 12 hours.
+	CookieMaxAge time.Duration
+
+	// CookieSameSite defaults to http.SameSiteLaxMode.
+	CookieSameSite http.SameSite
+
+	// TrustedOrigins lists additional origins, such as
+	// "https://app.example.com", allowed to send unsafe requests.
+	TrustedOrigins []string
+
+	// Exempt lists request paths or route patterns that are not
+	// checked, such as webhook receivers. Entries ending in "*" match
+	// by prefix.
+	Exempt []string
+}
+
+// CSRF returns a middleware that protects against cross-site request
+// forgery.
+//
+// Every request gets a token, available from c.CSRFToken() and in
+// templates as {{ csrfToken }} or {{ csrfField }}, which renders a
+// hidden form input. Requests with unsafe methods (anything other than
+// GET, HEAD, OPTIONS and TRACE) must:
+//
+//   - Come from the same origin or a trusted one, according to the
+//     Origin header or, when absent, the Referer header
+//   - Send the token in Header or FormField
+//
+// Failures are rejected with 403 Forbidden, wrapping ErrCSRFOrigin or
+// ErrCSRFToken.
+//
+// Example:
+//
+//	app.Use(mows.CSRF(mows.CSRFConfig{
+//	    Exempt: []string{"/webhooks/*"},
+//	}))
+//
+// and in a form template:
+//
+//	<form method="post">{{ csrfField }} ... </form>
+func CSRF(config CSRFConfig) Middleware {
+	if config.Mode == CSRFSynchronizer && config.Session == nil {
+		panic("mows: csrf synchronizer mode requires a session function")
+	}
+	if config.Store == nil {
+		config.Store = NewMemoryCSRFStore()
+	}
+	if config.Header == "" {
+		config.Header = "X-CSRF-Token"
+	}
+	if config.FormField == "" {
+		config.FormField = "csrf_token"
+	}
+	if config.CookieName == "" {
+		config.CookieName = "_csrf"
+	}
+	if config.CookiePath == "" {
+		config.CookiePath = "/"
+	}
+	if config.CookieMaxAge == 0 {
+		config.CookieMaxAge = 12 * time.Hour
+	}
+	if config.CookieSameSite == 0 {
+		config.CookieSameSite = http.SameSiteLaxMode
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			if config.exempt(c) {
+				return next(c)
+			}
+
+			token, err := config.token(c)
+			if err != nil {
+				return err
+			}
+
+			if !safeMethod(c.Request.Method) {
+				if !config.sameOrigin(c) {
+					return &HTTPError{Code: http.StatusForbidden, Message: http.StatusText(http.StatusForbidden), Err: ErrCSRFOrigin}
+				}
+
+				sent := c.Request.Header.Get(config.Header)
+				if sent == "" {
+					sent = c.Request.FormValue(config.FormField)
+				}
+				if sent == "" || subtle.ConstantTimeCompare([]byte(sent), []byte(token)) != 1 {
+					return &HTTPError{Code: http.StatusForbidden, Message: http.StatusText(http.StatusForbidden), Err: ErrCSRFToken}
+				}
+			}
+
+			c.Set(CSRFKey, token)
+			c.setTemplateFunc("csrfToken", func() string {
+				return token
+			})
+			c.setTemplateFunc("csrfField", func() template.HTML {
+				return template.HTML(`<input type="hidden" name="` + template.HTMLEscapeString(config.FormField) +
+					`" value="` + template.HTMLEscapeString(token) + `">`)
+			})
+
+			return next(c)
+		}
+	}
+}
+
+// CSRFToken returns the CSRF token of the request, or an empty string
+// if the CSRF middleware is not in use.
+func (c *Context) CSRFToken() string {
+	token, _ := c.keys[CSRFKey].(string)
+	return token
+}
+
+// token returns the valid token of the request, issuing a new one if needed.
+func (config *CSRFConfig) token(c *Context) (string, error) {
+	if config.Mode == CSRFSynchronizer {
+		session := config.Session(c)
+		if session == "" {
+			// without a session there is no token, so unsafe requests fail
+			return "", nil
+		}
+
+		token, err := config.Store.Get(c.Request.Context(), session)
+		if err != nil || token != "" {
+			return token, err
+		}
+		token = randomToken()
+		return token, config.Store.Set(c.Request.Context(), session, token)
+	}
+
+	var session string
+	if config.Session != nil && len(config.Secret) > 0 {
+		session = config.Session(c)
+	}
+
+	if cookie, err := c.Request.Cookie(config.CookieName); err == nil && config.validCookie(cookie.Value, session) {
+		return cookie.Value, nil
+	}
+
+	token := randomToken()
+	if len(config.Secret) > 0 {
+		token += "." + config.sign(token, session)
+	}
+
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     config.CookieName,
+		Value:    token,
+		Path:     config.CookiePath,
+		Domain:   config.CookieDomain,
+		MaxAge:   int(config.CookieMaxAge.Seconds()),
+		Secure:   c.Scheme() == "https",
+		HttpOnly: true,
+		SameSite: config.CookieSameSite,
+	})
+	return token, nil
+}
+
+// validCookie reports whether a double-submit cookie can be reused.
+func (config *CSRFConfig) validCookie(value, session string) bool {
+	if value == "" {
+		return false
+	}
+	if len(config.Secret) == 0 {
+		return true
+	}
+
+	random, signature, ok := strings.Cut(value, ".")
+	return ok && hmac.Equal([]byte(signature), []byte(config.sign(random, session)))
+}
+
+// sign binds a random token to a session.
+func (config *CSRFConfig) sign(random, session string) string {
+	mac := hmac.New(sha256.New, config.Secret)
+	mac.Write([]byte(session))
+	mac.Write([]byte{0})
+	mac.Write([]byte(random))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// exempt reports whether the request's path or route is exempt.
+func (config *CSRFConfig) exempt(c *Context) bool {
+	for _, pattern := range config.Exempt {
+		for _, candidate := range []string{c.Request.URL.Path, c.Route()} {
+			if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+				if strings.HasPrefix(candidate, prefix) {
+					return true
+				}
+			} else if candidate == pattern {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sameOrigin checks the Origin, or else the Referer, of an unsafe request.
+func (config *CSRFConfig) sameOrigin(c *Context) bool {
+	origin := c.Request.Header.Get("Origin")
+	if origin == "" {
+		referer := c.Request.Referer()
+		if referer == "" {
+			// non-browser clients send neither; the token check still applies
+			return true
+		}
+		u, err := url.Parse(referer)
+		if err != nil {
+			return false
+		}
+		origin = u.Scheme + "://" + u.Host
+	}
+
+	if strings.EqualFold(origin, c.Scheme()+"://"+c.Host()) {
+		return true
+	}
+	return slices.ContainsFunc(config.TrustedOrigins, func(trusted string) bool {
+		return strings.EqualFold(origin, trusted)
+	})
+}
+
+// safeMethod reports whether method is read-only per RFC 9110.
+func safeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// randomToken returns 32 random bytes, base64url encoded.
+func randomToken() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// MemoryCSRFStore is a CSRFStore held in memory.
+type MemoryCSRFStore struct {
+	mu     sync.Mutex
+	tokens map[string]string
+}
+
+// NewMemoryCSRFStore creates an empty MemoryCSRFStore.
+func NewMemoryCSRFStore() *MemoryCSRFStore {
+	return &MemoryCSRFStore{tokens: make(map[string]string)}
+}
+
+// Get implements CSRFStore.
+func (s *MemoryCSRFStore) Get(_ context.Context, session string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tokens[session], nil
+}
+
+// Set implements CSRFStore.
+func (s *MemoryCSRFStore) Set(_ context.Context, session, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[session] = token
+	return nil
+}
+
+// Delete removes the token of a session, e.g. on logout.
+func (s *MemoryCSRFStore) Delete(session string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tokens, session)
+}
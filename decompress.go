@@ -0,0 +1,97 @@
+package mows
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// DecompressConfig defines the configuration for the Decompress middleware.
+type DecompressConfig struct {
+	// MaxSize is the maximum size in bytes of a decompressed body.
+	// Reading past it fails with 413 Request Entity Too Large.
+	// Defaults to 10 MB.
+	MaxSize int64
+}
+
+// Decompress returns a middleware that transparently decodes request
+// bodies sent with Content-Encoding gzip or deflate.
+//
+// Binders such as BindJSON read the decoded body without any changes.
+// Unsupported encodings are rejected with 415 Unsupported Media Type.
+//
+// Example:
+//
+//	app.Use(mows.Decompress(mows.DecompressConfig{MaxSize: 1 << 20}))
+func Decompress(config DecompressConfig) Middleware {
+	if config.MaxSize <= 0 {
+		config.MaxSize = 10 << 20
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			encoding := strings.ToLower(strings.TrimSpace(c.Request.Header.Get("Content-Encoding")))
+			if encoding == "" || encoding == "identity" || c.Request.Body == nil || c.Request.Body == http.NoBody {
+				return next(c)
+			}
+
+			var (
+				reader io.ReadCloser
+				err    error
+			)
+			switch encoding {
+			case "gzip", "x-gzip":
+				reader, err = gzip.NewReader(c.Request.Body)
+			case "deflate":
+				reader, err = zlib.NewReader(c.Request.Body)
+			default:
+				return NewHTTPError(http.StatusUnsupportedMediaType, "unsupported content encoding: "+encoding)
+			}
+			if err != nil {
+				return &HTTPError{Code: http.StatusBadRequest, Message: "invalid " + encoding + " body", Err: err}
+			}
+
+			original := c.Request.Body
+			defer original.Close()
+
+			c.Request.Body = &limitedBody{reader: reader, remaining: config.MaxSize}
+			c.Request.Header.Del("Content-Encoding")
+			c.Request.Header.Del("Content-Length")
+			c.Request.ContentLength = -1
+
+			return next(c)
+		}
+	}
+}
+
+// limitedBody reads a decompressed body and fails once more than
+// the allowed number of bytes has been produced.
+type limitedBody struct {
+	reader    io.ReadCloser
+	remaining int64
+}
+
+func (l *limitedBody) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, NewHTTPError(http.StatusRequestEntityTooLarge, "decompressed body too large")
+	}
+
+	// read one byte past the limit to detect oversized bodies
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+
+	n, err := l.reader.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n, NewHTTPError(http.StatusRequestEntityTooLarge, "decompressed body too large")
+	}
+
+	return n, err
+}
+
+func (l *limitedBody) Close() error {
+	return l.reader.Close()
+}
@@ -0,0 +1,101 @@
+package mows
+
+import (
+	"bytes"
+	"embed"
+	"io/fs"
+	"net/http"
+	"strings"
+	"time"
+)
+
+//go:embed docsui
+var docsUI embed.FS
+
+// DocsConfig defines the configuration for the API explorer.
+type DocsConfig struct {
+	// Path serves the explorer. Defaults to "/docs".
+	Path string
+
+	// Enabled serves the explorer outside DevMode. By default it is only
+	// available in DevMode, so internal routes are not published by
+	// accident in production.
+	Enabled bool
+}
+
+// Docs serves an interactive API explorer at path, with the OpenAPI
+// document of the engine at path + "/openapi.json".
+//
+// The explorer is embedded in the binary and needs no network access.
+// It lists the documented routes (see Route) and sends requests to the
+// running server. It responds with 404 Not Found unless DevMode is
+// enabled; use DocsWithConfig to serve it in production.
+//
+// Example:
+//
+//	app.DevMode(true)
+//	app.Docs("/docs")
+func (e *Engine) Docs(path string) {
+	e.DocsWithConfig(DocsConfig{Path: path})
+}
+
+// DocsWithConfig serves the API explorer with the given configuration.
+// See Docs.
+func (e *Engine) DocsWithConfig(config DocsConfig) {
+	prefix := strings.TrimSuffix(config.Path, "/")
+	if prefix == "" {
+		prefix = "/docs"
+	}
+
+	enabled := func(c *Context) bool {
+		if config.Enabled || e.devMode {
+			return true
+		}
+		http.NotFound(c.Writer, c.Request)
+		return false
+	}
+
+	// relative asset URLs need the trailing slash
+	e.GET(prefix, func(c *Context) error {
+		if enabled(c) {
+			http.Redirect(c.Writer, c.Request, prefix+"/", http.StatusMovedPermanently)
+		}
+		return nil
+	}).Hidden()
+
+	e.GET(prefix+"/openapi.json", func(c *Context) error {
+		if !enabled(c) {
+			return nil
+		}
+		return c.JSON(http.StatusOK, e.OpenAPI())
+	}).Hidden()
+
+	assets, err := fs.Sub(docsUI, "docsui")
+	if err != nil {
+		panic(err)
+	}
+	err = fs.WalkDir(assets, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := fs.ReadFile(assets, name)
+		if err != nil {
+			return err
+		}
+
+		route := prefix + "/" + name
+		if name == "index.html" {
+			route = prefix + "/"
+		}
+		e.GET(route, func(c *Context) error {
+			if enabled(c) {
+				http.ServeContent(c.Writer, c.Request, name, time.Time{}, bytes.NewReader(data))
+			}
+			return nil
+		}).Hidden()
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+}
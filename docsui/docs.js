@@ -0,0 +1,274 @@
+// Offline API explorer for the OpenAPI document served next to this file.
+(function () {
+  "use strict";
+
+  var spec = null;
+
+  // el creates an element. Text is always set with textContent, so
+  // values from the specification are never parsed as HTML.
+  function el(tag, attrs) {
+    var node = document.createElement(tag);
+    Object.keys(attrs || {}).forEach(function (key) {
+      if (key === "text") {
+        node.textContent = attrs[key];
+      } else if (key === "class") {
+        node.className = attrs[key];
+      } else {
+        node.setAttribute(key, attrs[key]);
+      }
+    });
+    for (var i = 2; i < arguments.length; i++) {
+      if (arguments[i] != null) node.appendChild(arguments[i]);
+    }
+    return node;
+  }
+
+  function resolve(schema) {
+    var seen = 0;
+    while (schema && schema.$ref && seen++ < 32) {
+      var name = schema.$ref.replace("#/components/schemas/", "");
+      schema = (spec.components && spec.components.schemas && spec.components.schemas[name]) || {};
+    }
+    return schema || {};
+  }
+
+  function typeName(schema) {
+    if (!schema) return "";
+    if (schema.$ref) return schema.$ref.replace("#/components/schemas/", "");
+    if (schema.type === "array") return typeName(schema.items) + "[]";
+    var name = schema.type || "any";
+    if (schema.format) name += " (" + schema.format + ")";
+    if (schema.enum) name += " {" + schema.enum.join(", ") + "}";
+    return name;
+  }
+
+  // example builds a sample value from a schema, honoring constraints.
+  function example(schema, depth) {
+    schema = resolve(schema);
+    if (depth > 6) return null;
+    if (schema.enum && schema.enum.length) return schema.enum[0];
+
+    switch (schema.type) {
+      case "object":
+        var obj = {};
+        Object.keys(schema.properties || {}).forEach(function (key) {
+          obj[key] = example(schema.properties[key], depth + 1);
+        });
+        return obj;
+      case "array":
+        return [example(schema.items, depth + 1)];
+      case "integer":
+      case "number":
+        if (schema.minimum != null) return schema.minimum;
+        if (schema.exclusiveMinimum != null) return schema.exclusiveMinimum + 1;
+        return 0;
+      case "boolean":
+        return false;
+      case "string":
+        switch (schema.format) {
+          case "date-time": return new Date().toISOString();
+          case "email": return "user@example.com";
+          case "uuid": return "00000000-0000-0000-0000-000000000000";
+          case "uri": return "https://example.com";
+          case "ipv4": return "192.0.2.1";
+          case "ipv6": return "2001:db8::1";
+        }
+        var value = "string";
+        while (schema.minLength && value.length < schema.minLength) value += "string";
+        if (schema.maxLength) value = value.slice(0, schema.maxLength);
+        return value;
+    }
+    return null;
+  }
+
+  function baseURL() {
+    var value = document.getElementById("server").value;
+    return value.replace(/\/$/, "");
+  }
+
+  function renderSchema(schema) {
+    return el("pre", { text: JSON.stringify(example(schema, 0), null, 2) });
+  }
+
+  function renderOperation(path, method, op) {
+    var params = op.parameters || [];
+    var inputs = {};
+
+    var details = el("details", { class: "operation" + (op.deprecated ? " deprecated" : "") });
+    details.dataset.search = [path, op.summary || "", (op.tags || []).join(" ")].join(" ").toLowerCase();
+
+    details.appendChild(el("summary", {},
+      el("span", { class: "method " + method, text: method }),
+      el("span", { class: "path", text: path }),
+      el("span", { class: "summary", text: op.summary || "" })
+    ));
+
+    var body = el("div", { class: "body" });
+    details.appendChild(body);
+    if (op.description) body.appendChild(el("p", { class: "description", text: op.description }));
+
+    if (params.length) {
+      body.appendChild(el("h3", { text: "Parameters" }));
+      var table = el("table", {}, el("tr", {},
+        el("th", { text: "Name" }), el("th", { text: "In" }), el("th", { text: "Type" }), el("th", { text: "Value" })));
+      params.forEach(function (p) {
+        var input = el("input", { type: "text", placeholder: p.description || p.name });
+        inputs[p.in + ":" + p.name] = input;
+        table.appendChild(el("tr", {},
+          el("td", {}, document.createTextNode(p.name), p.required ? el("span", { class: "required", text: " *" }) : null),
+          el("td", { text: p.in }),
+          el("td", { class: "type", text: typeName(p.schema) }),
+          el("td", {}, input)
+        ));
+      });
+      body.appendChild(table);
+    }
+
+    var textarea = null;
+    var content = op.requestBody && op.requestBody.content && op.requestBody.content["application/json"];
+    if (content) {
+      body.appendChild(el("h3", { text: "Request body " }));
+      body.lastChild.appendChild(el("span", { class: "type", text: typeName(content.schema) }));
+      textarea = el("textarea", { spellcheck: "false" });
+      textarea.value = JSON.stringify(example(content.schema, 0), null, 2);
+      body.appendChild(textarea);
+    }
+
+    body.appendChild(el("h3", { text: "Responses" }));
+    Object.keys(op.responses || {}).sort().forEach(function (status) {
+      var resp = op.responses[status];
+      var json = resp.content && resp.content["application/json"];
+      body.appendChild(el("p", {},
+        el("span", { class: "status", text: status + " " }),
+        document.createTextNode(resp.description || ""),
+        json ? el("span", { class: "type", text: " " + typeName(json.schema) }) : null
+      ));
+      if (json) body.appendChild(renderSchema(json.schema));
+    });
+
+    var button = el("button", { class: "send", type: "button", text: "Send request" });
+    var result = el("div");
+    body.appendChild(button);
+    body.appendChild(result);
+
+    button.addEventListener("click", function () {
+      var url = path;
+      var query = new URLSearchParams();
+      var headers = {};
+      var missing = [];
+
+      params.forEach(function (p) {
+        var value = inputs[p.in + ":" + p.name].value;
+        if (value === "") {
+          if (p.required) missing.push(p.name);
+          return;
+        }
+        if (p.in === "path") url = url.replace("{" + p.name + "}", encodeURIComponent(value));
+        if (p.in === "query") query.append(p.name, value);
+        if (p.in === "header") headers[p.name] = value;
+      });
+      if (missing.length) {
+        result.replaceChildren(el("p", { class: "status error", text: "Missing required: " + missing.join(", ") }));
+        return;
+      }
+
+      var auth = document.getElementById("authorization").value;
+      if (auth && !headers.Authorization) headers.Authorization = auth;
+
+      var init = { method: method.toUpperCase(), headers: headers };
+      if (textarea && textarea.value.trim() !== "") {
+        headers["Content-Type"] = "application/json";
+        init.body = textarea.value;
+      }
+
+      var qs = query.toString();
+      var target = baseURL() + url + (qs ? "?" + qs : "");
+      var started = performance.now();
+      button.disabled = true;
+
+      fetch(target, init).then(function (res) {
+        return res.text().then(function (text) {
+          var elapsed = Math.round(performance.now() - started);
+          try {
+            text = JSON.stringify(JSON.parse(text), null, 2);
+          } catch (e) { /* not JSON, show as is */ }
+
+          var headerLines = [];
+          res.headers.forEach(function (value, name) { headerLines.push(name + ": " + value); });
+
+          result.replaceChildren(
+            el("h3", { text: "Response" }),
+            el("p", {},
+              el("span", { class: "status " + (res.ok ? "ok" : "error"), text: res.status + " " + res.statusText }),
+              document.createTextNode("  " + elapsed + " ms  " + init.method + " " + target)
+            ),
+            el("pre", { text: headerLines.join("\n") }),
+            el("h3", { text: "Body" }),
+            el("pre", { text: text || "(empty)" })
+          );
+        });
+      }).catch(function (err) {
+        result.replaceChildren(el("p", { class: "status error", text: "Request failed: " + err.message }));
+      }).finally(function () {
+        button.disabled = false;
+      });
+    });
+
+    return details;
+  }
+
+  function render() {
+    var info = spec.info || {};
+    document.title = (info.title || "API") + " docs";
+    document.getElementById("title").textContent = info.title || "API";
+    document.getElementById("version").textContent = info.version || "";
+    document.getElementById("description").textContent = info.description || "";
+
+    var server = document.getElementById("server");
+    server.appendChild(el("option", { value: "", text: location.origin }));
+    (spec.servers || []).forEach(function (s) {
+      server.appendChild(el("option", { value: s.url, text: s.description ? s.description + " (" + s.url + ")" : s.url }));
+    });
+
+    var groups = {};
+    Object.keys(spec.paths || {}).sort().forEach(function (path) {
+      var item = spec.paths[path];
+      Object.keys(item).forEach(function (method) {
+        var op = item[method];
+        var tag = (op.tags && op.tags[0]) || "default";
+        (groups[tag] = groups[tag] || []).push(renderOperation(path, method, op));
+      });
+    });
+
+    var container = document.getElementById("operations");
+    container.replaceChildren();
+    var tags = Object.keys(groups).sort();
+    if (!tags.length) container.appendChild(el("p", { class: "muted", text: "No routes are documented." }));
+    tags.forEach(function (tag) {
+      var section = el("section", {}, el("h2", { class: "tag", text: tag }));
+      groups[tag].forEach(function (node) { section.appendChild(node); });
+      container.appendChild(section);
+    });
+  }
+
+  document.getElementById("filter").addEventListener("input", function (e) {
+    var term = e.target.value.toLowerCase();
+    document.querySelectorAll(".operation").forEach(function (node) {
+      node.hidden = term !== "" && node.dataset.search.indexOf(term) < 0;
+    });
+    document.querySelectorAll("section").forEach(function (section) {
+      section.hidden = !section.querySelector(".operation:not([hidden])");
+    });
+  });
+
+  fetch("openapi.json").then(function (res) {
+    if (!res.ok) throw new Error(res.status + " " + res.statusText);
+    return res.json();
+  }).then(function (doc) {
+    spec = doc;
+    render();
+  }).catch(function (err) {
+    document.getElementById("operations").replaceChildren(
+      el("p", { class: "status error", text: "Could not load the specification: " + err.message }));
+  });
+})();
@@ -6,14 +6,11 @@
 package mows
 
 import (
-	"context"
-	"log"
+	"errors"
+	"log/slog"
 	"net/http"
-	"os"
-	"os/signal"
 	"path"
-	"syscall"
-	"time"
+	"slices"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -31,111 +28,92 @@ type Engine struct {
 	router       *Router
 	middlewares  []Middleware
 	server       *http.Server
+	serverConfig ServerConfig
+	lifecycle    lifecycle
+	logger       *slog.Logger
+	proxy        proxyConfig
 	rootGroup    *RouterGroup
 	validate     *validator.Validate
 	errorHandler ErrorHandler
 	templates    *TemplateEngine
 	devMode      bool
+	openapi      OpenAPIConfig
 }
 
 // New creates and returns a new Engine instance.
 //
+// Options can be passed to customize the engine.
+//
 // Example:
 //
 //	app := mows.New()
-func New() *Engine {
+//
+//	app := mows.New(mows.WithServerConfig(mows.ServerConfig{
+//	    ReadTimeout: 10 * time.Second,
+//	}))
+func New(opts ...Option) *Engine {
 	engine := &Engine{
-		router:   NewRouter(),
-		validate: validator.New(),
+		router:       NewRouter(),
+		validate:     validator.New(),
+		serverConfig: DefaultServerConfig(),
+		lifecycle:    lifecycle{config: DefaultShutdownConfig()},
+		logger:       slog.Default(),
 	}
 	engine.rootGroup = &RouterGroup{
 		engine: engine,
 	}
 	engine.errorHandler = defaultErrorHandler
 
+	for _, opt := range opts {
+		opt(engine)
+	}
+
 	return engine
 }
 
 // Run starts the HTTP server and listens on the given address.
 //
-// This is a helper wrapper around http.ListenAndServe.
+// This is a helper wrapper around http.ListenAndServe. If the process
+// was started with an inherited listener (systemd socket activation or
+// a graceful restart), that listener is used instead of addr.
 //
 // Example:
 //
 //	app.Run(":8080")
 func (e *Engine) Run(addr string) error {
-	e.server = &http.Server{
-		Addr:    addr,
-		Handler: http.HandlerFunc(e.handle),
-	}
-
-	// channel to listen to errors
-	serverErr := make(chan error, 1)
-
-	go func() {
-		log.Printf("🚀 Mows server running on %s", addr)
-		serverErr <- e.server.ListenAndServe()
-	}()
-
-	// listen for ctrl+c / sigterm
-	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
-
-	select {
-	case err := <-serverErr:
+	l, err := e.listen("tcp", addr)
+	if err != nil {
 		return err
-	case sig := <-stop:
-		log.Printf("🛑 Received signal: %s. Shutting down...", sig)
-		return e.shutdown()
 	}
+
+	return e.serve(l, func(srv *http.Server) error {
+		e.logger.Info("server started", "addr", l.Addr().String())
+		return srv.Serve(l)
+	})
 }
 
-// Run starts the HTTPS server and listens on the given address.
+// RunTLS starts the HTTPS server and listens on the given address.
 //
-// This is a helper wrapper around http.ListenAndServeTLS.
+// This is a helper wrapper around http.ListenAndServeTLS using
+// DefaultTLSConfig. Use RunTLSConfig for certificate reloading,
+// multiple certificates or mutual TLS.
 //
 // Example:
 //
-//	app.RunTLS(":443")
+//	app.RunTLS(":443", "cert.pem", "key.pem")
 func (e *Engine) RunTLS(addr, certFile, keyFile string) error {
-	e.server = &http.Server{
-		Addr:    addr,
-		Handler: http.HandlerFunc(e.handle),
-	}
-
-	serverErr := make(chan error, 1)
-
-	go func() {
-		log.Printf("🔒 Mows HTTPS server running on %s", addr)
-		serverErr <- e.server.ListenAndServeTLS(certFile, keyFile)
-	}()
-
-	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
-
-	select {
-	case err := <-serverErr:
-		return err
-	case sig := <-stop:
-		log.Printf("🛑 Received signal: %s. Shutting down...", sig)
-		return e.shutdown()
-	}
-}
-
-// shutdown gracefully stops the HTTP server.
-// It is used internally for graceful shutdown support.
-func (e *Engine) shutdown() error {
-	// wait 5 seconds for active requests
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	if err := e.server.Shutdown(ctx); err != nil {
-		log.Printf("❌ Graceful shutdown failed: %v", err)
+	l, err := e.listen("tcp", addr)
+	if err != nil {
 		return err
 	}
 
-	log.Println("✅ Server stopped gracefully")
-	return nil
+	return e.serve(l, func(srv *http.Server) error {
+		if srv.TLSConfig == nil {
+			srv.TLSConfig = DefaultTLSConfig()
+		}
+		e.logger.Info("server started", "addr", l.Addr().String(), "tls", true)
+		return srv.ServeTLS(l, certFile, keyFile)
+	})
 }
 
 // ServeHTTP implements the http.Handler interface.
@@ -171,9 +149,14 @@ func (e *Engine) handle(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx.Params = params
+	ctx.route = route.pattern
+	ctx.meta = route.meta
 
 	// route-specific chain
 	h := route.handler
+	if route.meta != nil && len(route.meta.Requirements) > 0 {
+		h = requireAuthorized(h)
+	}
 	for i := len(route.middlewares) - 1; i >= 0; i-- {
 		h = route.middlewares[i](h)
 	}
@@ -181,7 +164,7 @@ func (e *Engine) handle(w http.ResponseWriter, r *http.Request) {
 	// global middlewares
 	final := e.buildChain(h)
 	if err := final(ctx); err != nil {
-		e.errorHandler(ctx, err)
+		ctx.handleError(err)
 	}
 }
 
@@ -200,7 +183,7 @@ func (e *Engine) Group(prefix string, m ...Middleware) *RouterGroup {
 
 // addRoute registers a new route in the router.
 // It is used internally by HTTP method helpers (GET, POST, etc).
-func (e *Engine) addRoute(method string, path string, middlewares []Middleware, handlers ...HandlerFunc) {
+func (e *Engine) addRoute(group *RouterGroup, method string, path string, handlers ...HandlerFunc) *Route {
 	if len(handlers) == 0 {
 		panic("route must have at least one handler")
 	}
@@ -208,14 +191,38 @@ func (e *Engine) addRoute(method string, path string, middlewares []Middleware,
 	final := handlers[len(handlers)-1]
 	routeHandlers := handlers[:len(handlers)-1]
 
+	meta := group.meta()
+	name := funcName(final)
+	if types := describeHandler(final); types != nil {
+		types.document(meta)
+		name = types.name
+	}
+
 	var routeMiddlewares []Middleware
+	var names []string
+	var policies []Policy
+	for _, m := range group.middlewares {
+		names = append(names, middlewareName(m))
+		if policy := authorizePolicy(m); policy != nil {
+			policies = append(policies, policy)
+		}
+	}
 	for _, h := range routeHandlers {
 		routeMiddlewares = append(routeMiddlewares, wrapHandlerAsMiddleware(h))
+		names = append(names, funcName(h))
 	}
 
-	allMiddlewares := append(middlewares, routeMiddlewares...)
-
-	e.router.addWithMiddleware(method, path, final, allMiddlewares)
+	e.router.add(method, route{
+		pattern:         group.prefix + path,
+		handler:         final,
+		middlewares:     slices.Concat(group.middlewares, routeMiddlewares),
+		meta:            meta,
+		group:           group.prefix,
+		handlerName:     name,
+		middlewareNames: names,
+		policies:        policies,
+	})
+	return &Route{meta: meta}
 }
 
 // ErrorHandler defines a centralized error handling function.
@@ -224,12 +231,39 @@ func (e *Engine) addRoute(method string, path string, middlewares []Middleware,
 type ErrorHandler func(*Context, error)
 
 // defaultErrorHandler is the fallback error handler used by the Engine.
+//
+// It responds with the status code of an HTTPError, 500 for a
+// PanicError, or 400 otherwise.
 func defaultErrorHandler(c *Context, err error) {
-	c.JSON(http.StatusBadRequest, serverError{
-		Error: err.Error(),
+	var panicErr *PanicError
+	if errors.As(err, &panicErr) {
+		writePanic(c, panicErr)
+		return
+	}
+
+	code := http.StatusBadRequest
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		code = httpErr.Code
+	}
+
+	c.JSON(code, serverError{
+		Error:     err.Error(),
+		RequestID: c.RequestID(),
 	})
 }
 
+// SetLogger replaces the logger used by the engine and by Context.Logger.
+//
+// The default is slog.Default().
+//
+// Example:
+//
+//	app.SetLogger(slog.New(slog.NewJSONHandler(os.Stdout, nil)))
+func (e *Engine) SetLogger(logger *slog.Logger) {
+	e.logger = logger
+}
+
 // SetErrorHandler replaces the default error handler.
 //
 // This allows applications to customize how errors are returned.
@@ -261,6 +295,9 @@ func (e *Engine) Static(prefix string, root string) {
 // When enabled, Engine will reload filesystem templates before each render,
 // allowing developers to see changes instantly without restarting the server.
 //
+// The route table is also printed to stderr when the server starts
+// (see Engine.WriteRoutes).
+//
 // Note: Hot reload does NOT apply to templates loaded from embed.FS.
 func (e *Engine) DevMode(enable bool) {
 	e.devMode = enable
@@ -1,9 +1,46 @@
 package mows
 
-import "errors"
+import (
+	"errors"
+	"net/http"
+)
 
 var ErrTemplatesNotLoaded = errors.New("templates not loaded")
 
+// HTTPError is an error that carries an HTTP status code.
+//
+// Handlers and middleware can return an HTTPError to control the
+// status code used by the default error handler.
+//
+// Example:
+//
+//	return mows.NewHTTPError(http.StatusNotFound, "user not found")
+type HTTPError struct {
+	Code    int
+	Message string
+	Err     error
+}
+
+// NewHTTPError creates an HTTPError with the given status code and message.
+//
+// If message is empty, the standard status text is used.
+func NewHTTPError(code int, message string) *HTTPError {
+	if message == "" {
+		message = http.StatusText(code)
+	}
+	return &HTTPError{Code: code, Message: message}
+}
+
+// Error implements the error interface.
+func (e *HTTPError) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the wrapped error, if any.
+func (e *HTTPError) Unwrap() error {
+	return e.Err
+}
+
 // serverError represents a JSON structure for internal server errors.
 //
 // It is used to send a consistent error response when a handler fails
@@ -11,7 +48,8 @@ var ErrTemplatesNotLoaded = errors.New("templates not loaded")
 //
 // Example JSON response:
 //
-//	{ "error": "something went wrong" }
+//	{ "error": "something went wrong", "request_id": "0190..." }
 type serverError struct {
-	Error string `json:"error"`
+	Error     string `json:"error"`
+	RequestID string `json:"request_id,omitempty"`
 }
@@ -2,14 +2,17 @@ module github.com/saintmili/mows
 
 go 1.25.5
 
-require github.com/go-playground/validator/v10 v10.30.1
+require (
+	github.com/go-playground/validator/v10 v10.30.1
+	golang.org/x/crypto v0.46.0
+	golang.org/x/net v0.47.0
+)
 
 require (
 	github.com/gabriel-vasile/mimetype v1.4.12 // indirect
 	github.com/go-playground/locales v0.14.1 // indirect
 	github.com/go-playground/universal-translator v0.18.1 // indirect
 	github.com/leodido/go-urn v1.4.0 // indirect
-	golang.org/x/crypto v0.46.0 // indirect
 	golang.org/x/sys v0.39.0 // indirect
 	golang.org/x/text v0.32.0 // indirect
 )
@@ -0,0 +1,203 @@
+package mows
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// listenFDsStart is the first inherited file descriptor, as defined
+	// by the systemd socket activation protocol.
+	listenFDsStart = 3
+
+	envListenFDs     = "LISTEN_FDS"
+	envListenPID     = "LISTEN_PID"
+	envListenFDNames = "LISTEN_FDNAMES"
+	envReadyFD       = "MOWS_READY_FD"
+)
+
+var (
+	inheritOnce sync.Once
+	inherited   []net.Listener
+	inheritErr  error
+)
+
+// InheritedListeners returns the listeners passed to this process,
+// either by systemd socket activation or by a graceful restart.
+//
+// It follows the LISTEN_FDS protocol: descriptors start at 3, and
+// LISTEN_PID, when set, must match the current process. The result
+// is computed once; later calls return the same listeners.
+//
+// Run, RunTLS and RunUnix adopt these listeners automatically, in order.
+func InheritedListeners() ([]net.Listener, error) {
+	inheritOnce.Do(func() {
+		inherited, inheritErr = listenersFromEnv()
+	})
+	return inherited, inheritErr
+}
+
+// listenersFromEnv wraps the inherited file descriptors as listeners.
+func listenersFromEnv() ([]net.Listener, error) {
+	defer func() {
+		os.Unsetenv(envListenFDs)
+		os.Unsetenv(envListenPID)
+		os.Unsetenv(envListenFDNames)
+	}()
+
+	count := os.Getenv(envListenFDs)
+	if count == "" {
+		return nil, nil
+	}
+
+	if pid := os.Getenv(envListenPID); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return nil, nil
+	}
+
+	n, err := strconv.Atoi(count)
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("mows: invalid %s=%q", envListenFDs, count)
+	}
+
+	listeners := make([]net.Listener, 0, n)
+	for i := 0; i < n; i++ {
+		f := os.NewFile(uintptr(listenFDsStart+i), "listener-"+strconv.Itoa(i))
+		l, err := net.FileListener(f)
+		f.Close()
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return nil, fmt.Errorf("mows: inherited fd %d: %w", listenFDsStart+i, err)
+		}
+		listeners = append(listeners, l)
+	}
+
+	return listeners, nil
+}
+
+// inheritedListener returns the next unused inherited listener, or nil
+// if there are none left.
+func (e *Engine) inheritedListener() (net.Listener, error) {
+	listeners, err := InheritedListeners()
+	if err != nil {
+		return nil, err
+	}
+
+	if e.lifecycle.inherited >= len(listeners) {
+		return nil, nil
+	}
+
+	l := listeners[e.lifecycle.inherited]
+	e.lifecycle.inherited++
+	return l, nil
+}
+
+// restart starts a new copy of the process that inherits l, and waits
+// until it reports readiness or RestartTimeout expires.
+//
+// The listener is passed as fd 3 with LISTEN_FDS=1; a pipe on fd 4 is
+// closed by the child once it is serving.
+func (e *Engine) restart(l net.Listener) error {
+	filer, ok := l.(interface{ File() (*os.File, error) })
+	if !ok {
+		return errors.New("mows: listener does not support file descriptor passing")
+	}
+
+	f, err := filer.File()
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	ready, readyW, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer ready.Close()
+
+	cmd := exec.Command(exe, os.Args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = []*os.File{f, readyW}
+	cmd.Env = append(restartEnv(),
+		envListenFDs+"=1",
+		envReadyFD+"="+strconv.Itoa(listenFDsStart+1),
+	)
+
+	err = cmd.Start()
+	readyW.Close()
+	if err != nil {
+		return err
+	}
+
+	result := make(chan error, 1)
+	go func() {
+		buf := make([]byte, 1)
+		_, err := ready.Read(buf)
+		result <- err
+	}()
+
+	select {
+	case err := <-result:
+		if err != nil {
+			cmd.Wait()
+			return fmt.Errorf("mows: new process exited before becoming ready: %w", err)
+		}
+	case <-time.After(e.lifecycle.config.RestartTimeout):
+		cmd.Process.Kill()
+		cmd.Wait()
+		return errors.New("mows: new process did not become ready in time")
+	}
+
+	e.lifecycle.handedOff.Store(true)
+	return nil
+}
+
+// restartEnv returns the current environment without listener variables.
+func restartEnv() []string {
+	var env []string
+	for _, kv := range os.Environ() {
+		switch {
+		case strings.HasPrefix(kv, envListenFDs+"="),
+			strings.HasPrefix(kv, envListenPID+"="),
+			strings.HasPrefix(kv, envListenFDNames+"="),
+			strings.HasPrefix(kv, envReadyFD+"="):
+			continue
+		}
+		env = append(env, kv)
+	}
+	return env
+}
+
+// notifyParentReady tells the process that started this one during a
+// graceful restart that the listener has been taken over.
+func notifyParentReady() {
+	fd := os.Getenv(envReadyFD)
+	if fd == "" {
+		return
+	}
+	os.Unsetenv(envReadyFD)
+
+	n, err := strconv.Atoi(fd)
+	if err != nil {
+		return
+	}
+
+	f := os.NewFile(uintptr(n), "ready")
+	f.Write([]byte{1})
+	f.Close()
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package mows
+
+import "os"
+
+// restartSignals is empty on platforms without SIGHUP/SIGUSR2, so
+// graceful restarts are never triggered there.
+var restartSignals []os.Signal
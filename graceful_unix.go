@@ -0,0 +1,11 @@
+//go:build unix
+
+package mows
+
+import (
+	"os"
+	"syscall"
+)
+
+// restartSignals trigger a graceful restart when GracefulRestart is enabled.
+var restartSignals = []os.Signal{syscall.SIGHUP, syscall.SIGUSR2}
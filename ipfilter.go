@@ -0,0 +1,193 @@
+package mows
+
+import (
+	"net/http"
+	"net/netip"
+	"sync/atomic"
+)
+
+// IPFilterConfig defines the configuration for the IPFilter middleware.
+type IPFilterConfig struct {
+	// Allow lists the CIDR ranges (or single IPs) allowed to connect.
+	// When empty, every address not denied is allowed.
+	Allow []string
+
+	// Deny lists the CIDR ranges (or single IPs) that are rejected.
+	// Deny takes precedence over Allow.
+	Deny []string
+
+	// List, when set, is used instead of Allow and Deny. Its rules can
+	// be replaced at runtime with IPAccessList.Update.
+	List *IPAccessList
+}
+
+// IPFilter returns a middleware that restricts access by client IP
+// address, rejecting other requests with 403 Forbidden.
+//
+// The client IP is resolved with Context.ClientIP, so requests behind
+// trusted proxies are filtered by the real client address. IPv4 and
+// IPv6 ranges can be mixed. It panics if an entry is not a valid IP
+// or CIDR range.
+//
+// Example:
+//
+//	admin := app.Group("/admin", mows.IPFilter(mows.IPFilterConfig{
+//	    Allow: []string{"10.8.0.0/16", "fd10::/64"},
+//	}))
+func IPFilter(config IPFilterConfig) Middleware {
+	list := config.List
+	if list == nil {
+		var err error
+		list, err = NewIPAccessList(config.Allow, config.Deny)
+		if err != nil {
+			panic("mows: " + err.Error())
+		}
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			if !list.Allowed(c.ClientIP()) {
+				return NewHTTPError(http.StatusForbidden, "")
+			}
+			return next(c)
+		}
+	}
+}
+
+// IPAccessList is a set of allow and deny rules that can be updated
+// while the server is running.
+//
+// Example:
+//
+//	vpn, _ := mows.NewIPAccessList([]string{"10.8.0.0/16"}, nil)
+//	admin := app.Group("/admin", mows.IPFilter(mows.IPFilterConfig{List: vpn}))
+//
+//	// later, e.g. after reloading configuration
+//	vpn.Update([]string{"10.8.0.0/16", "10.9.0.0/16"}, nil)
+type IPAccessList struct {
+	rules atomic.Pointer[ipRules]
+}
+
+// ipRules holds one immutable set of allow and deny tries.
+type ipRules struct {
+	allow *prefixTrie
+	deny  *prefixTrie
+}
+
+// NewIPAccessList creates an IPAccessList from CIDR ranges or single IPs.
+//
+// It returns an error if any entry is invalid.
+func NewIPAccessList(allow, deny []string) (*IPAccessList, error) {
+	l := &IPAccessList{}
+	if err := l.Update(allow, deny); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Update atomically replaces the rules.
+//
+// Requests in flight finish with the old rules. On error the existing
+// rules are kept.
+func (l *IPAccessList) Update(allow, deny []string) error {
+	rules := &ipRules{}
+
+	var err error
+	if rules.allow, err = newPrefixTrie(allow); err != nil {
+		return err
+	}
+	if rules.deny, err = newPrefixTrie(deny); err != nil {
+		return err
+	}
+
+	l.rules.Store(rules)
+	return nil
+}
+
+// Allowed reports whether ip passes the rules.
+func (l *IPAccessList) Allowed(ip string) bool {
+	addr, ok := parseIP(ip)
+	if !ok {
+		return false
+	}
+
+	rules := l.rules.Load()
+	if rules.deny.contains(addr) {
+		return false
+	}
+	return rules.allow.empty() || rules.allow.contains(addr)
+}
+
+// prefixTrie is a binary trie of IP prefixes, with separate roots for
+// IPv4 and IPv6. Lookups take at most 32 or 128 steps regardless of
+// the number of prefixes.
+type prefixTrie struct {
+	v4, v6 *trieNode
+	size   int
+}
+
+type trieNode struct {
+	children [2]*trieNode
+	terminal bool
+}
+
+// newPrefixTrie builds a trie from CIDR ranges or single IPs.
+func newPrefixTrie(cidrs []string) (*prefixTrie, error) {
+	t := &prefixTrie{v4: &trieNode{}, v6: &trieNode{}}
+	for _, cidr := range cidrs {
+		prefix, err := parsePrefix(cidr)
+		if err != nil {
+			return nil, err
+		}
+		t.insert(prefix)
+	}
+	return t, nil
+}
+
+func (t *prefixTrie) root(addr netip.Addr) *trieNode {
+	if addr.Is4() {
+		return t.v4
+	}
+	return t.v6
+}
+
+// insert adds prefix to the trie.
+func (t *prefixTrie) insert(prefix netip.Prefix) {
+	addr := prefix.Addr()
+	bytes := addr.AsSlice()
+
+	node := t.root(addr)
+	for i := 0; i < prefix.Bits(); i++ {
+		bit := bytes[i/8] >> (7 - i%8) & 1
+		if node.children[bit] == nil {
+			node.children[bit] = &trieNode{}
+		}
+		node = node.children[bit]
+	}
+
+	node.terminal = true
+	t.size++
+}
+
+// contains reports whether addr falls within any prefix of the trie.
+func (t *prefixTrie) contains(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	bytes := addr.AsSlice()
+
+	node := t.root(addr)
+	for i := 0; node != nil; i++ {
+		if node.terminal {
+			return true
+		}
+		if i == len(bytes)*8 {
+			return false
+		}
+		node = node.children[bytes[i/8]>>(7-i%8)&1]
+	}
+
+	return false
+}
+
+func (t *prefixTrie) empty() bool {
+	return t.size == 0
+}
@@ -0,0 +1,397 @@
+package mows
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// KeySet provides the keys used to verify tokens.
+type KeySet interface {
+	// Key returns the key for kid. kid is empty when the token header
+	// has none. It returns an error wrapping ErrTokenUnknownKey if no
+	// such key exists.
+	Key(ctx context.Context, kid string) (any, error)
+}
+
+// StaticKeySet is a fixed KeySet mapping key IDs to keys.
+//
+// Values may be []byte or string HMAC secrets, or RSA, ECDSA (P-256)
+// and Ed25519 public or private keys. A token without a "kid" header
+// matches when the set holds exactly one key.
+type StaticKeySet map[string]any
+
+// Key implements KeySet.
+func (s StaticKeySet) Key(_ context.Context, kid string) (any, error) {
+	if key, ok := s.lookup(kid); ok {
+		return key, nil
+	}
+	return nil, ErrTokenUnknownKey
+}
+
+func (s StaticKeySet) lookup(kid string) (any, bool) {
+	if key, ok := s[kid]; ok {
+		return key, true
+	}
+	if kid == "" && len(s) == 1 {
+		for _, key := range s {
+			return key, true
+		}
+	}
+	return nil, false
+}
+
+// JSONWebKey is a single key of a JWKS document (RFC 7517).
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid,omitempty"`
+	Algorithm string `json:"alg,omitempty"`
+	Use       string `json:"use,omitempty"`
+
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// EC and OKP
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+	Y     string `json:"y,omitempty"`
+
+	// oct
+	K string `json:"k,omitempty"`
+}
+
+// newJSONWebKey encodes a public key as a JWK.
+func newJSONWebKey(key any) (JSONWebKey, bool) {
+	enc := base64.RawURLEncoding
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return JSONWebKey{
+			KeyType: "RSA",
+			N:       enc.EncodeToString(k.N.Bytes()),
+			E:       enc.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+		}, true
+
+	case *ecdsa.PublicKey:
+		point, err := k.Bytes()
+		if err != nil || k.Curve != elliptic.P256() {
+			return JSONWebKey{}, false
+		}
+		return JSONWebKey{
+			KeyType: "EC",
+			Curve:   "P-256",
+			X:       enc.EncodeToString(point[1:33]),
+			Y:       enc.EncodeToString(point[33:]),
+		}, true
+
+	case ed25519.PublicKey:
+		return JSONWebKey{
+			KeyType: "OKP",
+			Curve:   "Ed25519",
+			X:       enc.EncodeToString(k),
+		}, true
+	}
+
+	return JSONWebKey{}, false
+}
+
+// Key decodes the JWK into a key usable for verification.
+func (k JSONWebKey) Key() (any, error) {
+	enc := base64.RawURLEncoding
+
+	switch k.KeyType {
+	case "RSA":
+		n, err := enc.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := enc.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+
+	case "EC":
+		if k.Curve != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Curve)
+		}
+		x, err := enc.DecodeString(k.X)
+		if err != nil || len(x) != 32 {
+			return nil, fmt.Errorf("invalid EC x coordinate")
+		}
+		y, err := enc.DecodeString(k.Y)
+		if err != nil || len(y) != 32 {
+			return nil, fmt.Errorf("invalid EC y coordinate")
+		}
+		point := append(append([]byte{4}, x...), y...)
+		return ecdsa.ParseUncompressedPublicKey(elliptic.P256(), point)
+
+	case "OKP":
+		if k.Curve != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Curve)
+		}
+		x, err := enc.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+
+	case "oct":
+		return enc.DecodeString(k.K)
+	}
+
+	return nil, fmt.Errorf("unsupported key type %q", k.KeyType)
+}
+
+// JWKSConfig defines the configuration of a JWKS key set.
+type JWKSConfig struct {
+	// Source is the path of a JWKS file or an http(s) URL serving one.
+	Source string
+
+	// RefreshInterval is how long fetched keys are used before the
+	// document is loaded again. Defaults to 1 hour.
+	RefreshInterval time.Duration
+
+	// MinRefreshInterval limits how often an unknown "kid" triggers an
+	// early reload, e.g. after key rotation. Defaults to 1 minute.
+	MinRefreshInterval time.Duration
+
+	// Client fetches URL sources. Defaults to a client with a
+	// 10 second timeout.
+	Client *http.Client
+
+	// FetchTimeout bounds each load of the document. Loads are shared
+	// by concurrent requests and do not use their contexts, so a client
+	// that disconnects cannot cancel them. Defaults to 10 seconds.
+	FetchTimeout time.Duration
+}
+
+// JWKSKeySet is a KeySet loaded from a JWKS document and cached.
+//
+// Keys are reloaded every RefreshInterval, and early when a token
+// names an unknown key. If a reload fails, the previous keys stay in use.
+type JWKSKeySet struct {
+	config JWKSConfig
+
+	mu          sync.Mutex
+	keys        map[string]any
+	err         error
+	lastAttempt time.Time
+	fetched     time.Time
+	inflight    *jwksFetch
+}
+
+// jwksFetch is a load of the document shared by concurrent callers.
+type jwksFetch struct {
+	done chan struct{}
+	err  error
+}
+
+// NewJWKSKeySet creates a key set for a JWKS file or URL.
+//
+// Keys are loaded on first use. Call Refresh, e.g. from an OnStart
+// hook, to load them up front and fail fast.
+//
+// Example:
+//
+//	keys := mows.NewJWKSKeySet(mows.JWKSConfig{
+//	    Source: "http://auth.internal/.well-known/jwks.json",
+//	})
+//	app.OnStart(keys.Refresh)
+//	api := app.Group("/api", mows.JWT(mows.JWTConfig{Keys: keys}))
+func NewJWKSKeySet(config JWKSConfig) *JWKSKeySet {
+	if config.Source == "" {
+		panic("mows: jwks requires a source")
+	}
+	if config.RefreshInterval <= 0 {
+		config.RefreshInterval = time.Hour
+	}
+	if config.MinRefreshInterval <= 0 {
+		config.MinRefreshInterval = time.Minute
+	}
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if config.FetchTimeout <= 0 {
+		config.FetchTimeout = 10 * time.Second
+	}
+	return &JWKSKeySet{config: config}
+}
+
+// Key implements KeySet.
+//
+// Stale keys are served while a reload runs in the background; callers
+// only wait for a load when no keys have been loaded yet or kid is
+// unknown. ctx bounds the wait, not the load itself.
+func (s *JWKSKeySet) Key(ctx context.Context, kid string) (any, error) {
+	s.mu.Lock()
+	loaded := s.keys != nil
+	stale := !loaded || time.Since(s.fetched) > s.config.RefreshInterval
+	canRefresh := s.canRefresh()
+	s.mu.Unlock()
+
+	if stale && canRefresh {
+		f := s.fetch()
+		if !loaded {
+			if err := s.wait(ctx, f); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	keys, err := s.current()
+	if keys == nil {
+		return nil, err
+	}
+	if key, ok := StaticKeySet(keys).lookup(kid); ok {
+		return key, nil
+	}
+
+	// the key may have been rotated since the last load
+	s.mu.Lock()
+	canRefresh = s.canRefresh()
+	s.mu.Unlock()
+	if canRefresh && s.wait(ctx, s.fetch()) == nil {
+		keys, _ = s.current()
+		if key, ok := StaticKeySet(keys).lookup(kid); ok {
+			return key, nil
+		}
+	}
+
+	return nil, ErrTokenUnknownKey
+}
+
+// Refresh loads the JWKS document now, or waits for a load already in
+// progress.
+func (s *JWKSKeySet) Refresh(ctx context.Context) error {
+	return s.wait(ctx, s.fetch())
+}
+
+// current returns the loaded keys and the error of the last load.
+func (s *JWKSKeySet) current() (map[string]any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.keys, s.err
+}
+
+// canRefresh reports whether a load may be started or joined. Failed
+// loads are retried at most every MinRefreshInterval. s.mu must be held.
+func (s *JWKSKeySet) canRefresh() bool {
+	return s.inflight != nil || time.Since(s.lastAttempt) > s.config.MinRefreshInterval
+}
+
+// fetch starts a load of the document unless one is in progress, and
+// returns it.
+func (s *JWKSKeySet) fetch() *jwksFetch {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.inflight != nil {
+		return s.inflight
+	}
+	f := &jwksFetch{done: make(chan struct{})}
+	s.inflight = f
+	s.lastAttempt = time.Now()
+	go s.run(f)
+	return f
+}
+
+// wait waits for f to finish or ctx to be done.
+func (s *JWKSKeySet) wait(ctx context.Context, f *jwksFetch) error {
+	select {
+	case <-f.done:
+		return f.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run loads the document without holding s.mu, with its own timeout,
+// and replaces the keys on success.
+func (s *JWKSKeySet) run(f *jwksFetch) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.FetchTimeout)
+	defer cancel()
+
+	keys, err := s.parse(ctx)
+
+	s.mu.Lock()
+	if err == nil {
+		s.keys = keys
+		s.fetched = time.Now()
+	}
+	s.err = err
+	s.inflight = nil
+	s.mu.Unlock()
+
+	f.err = err
+	close(f.done)
+}
+
+// parse loads the document and returns its signing keys.
+func (s *JWKSKeySet) parse(ctx context.Context) (map[string]any, error) {
+	data, err := s.load(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("mows: loading jwks: %w", err)
+	}
+
+	var doc struct {
+		Keys []JSONWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("mows: parsing jwks: %w", err)
+	}
+
+	keys := make(map[string]any, len(doc.Keys))
+	for _, jwk := range doc.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.Key()
+		if err != nil {
+			// skip keys of unsupported types
+			continue
+		}
+		keys[jwk.KeyID] = key
+	}
+	return keys, nil
+}
+
+// load reads the document from the file or URL source.
+func (s *JWKSKeySet) load(ctx context.Context) ([]byte, error) {
+	source := s.config.Source
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		return os.ReadFile(source)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.config.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
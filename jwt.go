@@ -0,0 +1,527 @@
+package mows
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Signing algorithms supported by JWT and JWTSigner.
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	ES256 = "ES256"
+	EdDSA = "EdDSA"
+)
+
+// JWTKey is the Context store key holding the verified *Token.
+const JWTKey = "jwt"
+
+// Errors reported when a token fails verification. They are wrapped in
+// the HTTPError returned by the JWT middleware.
+var (
+	ErrTokenMalformed      = errors.New("token is malformed")
+	ErrTokenAlgorithm      = errors.New("token algorithm is not allowed")
+	ErrTokenUnknownKey     = errors.New("token signing key is unknown")
+	ErrTokenSignature      = errors.New("token signature is invalid")
+	ErrTokenExpired        = errors.New("token is expired")
+	ErrTokenNotYetValid    = errors.New("token is not valid yet")
+	ErrTokenIssuedInFuture = errors.New("token is issued in the future")
+	ErrTokenIssuer         = errors.New("token issuer is invalid")
+	ErrTokenAudience       = errors.New("token audience is invalid")
+)
+
+// NumericDate is a JWT time value, encoded as seconds since the epoch.
+type NumericDate struct {
+	time.Time
+}
+
+// NewNumericDate returns t truncated to whole seconds.
+func NewNumericDate(t time.Time) *NumericDate {
+	return &NumericDate{t.Truncate(time.Second)}
+}
+
+// MarshalJSON encodes the date as a number of seconds.
+func (d NumericDate) MarshalJSON() ([]byte, error) {
+	return strconv.AppendInt(nil, d.Unix(), 10), nil
+}
+
+// UnmarshalJSON decodes an integer or fractional number of seconds.
+func (d *NumericDate) UnmarshalJSON(b []byte) error {
+	seconds, err := strconv.ParseFloat(string(b), 64)
+	if err != nil {
+		return ErrTokenMalformed
+	}
+	whole := int64(seconds)
+	d.Time = time.Unix(whole, int64((seconds-float64(whole))*1e9))
+	return nil
+}
+
+// Audience is the "aud" claim. It decodes from a single string or an
+// array of strings.
+type Audience []string
+
+// MarshalJSON encodes a single audience as a string.
+func (a Audience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+	return json.Marshal([]string(a))
+}
+
+// UnmarshalJSON decodes a string or an array of strings.
+func (a *Audience) UnmarshalJSON(b []byte) error {
+	var single string
+	if err := json.Unmarshal(b, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(b, &list); err != nil {
+		return ErrTokenMalformed
+	}
+	*a = list
+	return nil
+}
+
+// RegisteredClaims are the claims defined by RFC 7519.
+//
+// Embed it in custom claim types:
+//
+//	type UserClaims struct {
+//	    mows.RegisteredClaims
+//	    Role string `json:"role"`
+//	}
+type RegisteredClaims struct {
+	Issuer    string       `json:"iss,omitempty"`
+	Subject   string       `json:"sub,omitempty"`
+	Audience  Audience     `json:"aud,omitempty"`
+	ExpiresAt *NumericDate `json:"exp,omitempty"`
+	NotBefore *NumericDate `json:"nbf,omitempty"`
+	IssuedAt  *NumericDate `json:"iat,omitempty"`
+	ID        string       `json:"jti,omitempty"`
+}
+
+// JWTHeader is the JOSE header of a token.
+type JWTHeader struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ,omitempty"`
+	KeyID     string `json:"kid,omitempty"`
+}
+
+// Token is a verified JSON Web Token.
+type Token struct {
+	// Header is the decoded JOSE header.
+	Header JWTHeader
+
+	// Claims holds the registered claims.
+	Claims RegisteredClaims
+
+	// Raw is the encoded token.
+	Raw string
+
+	payload []byte
+}
+
+// Decode unmarshals the token's claims into v.
+func (t *Token) Decode(v any) error {
+	return json.Unmarshal(t.payload, v)
+}
+
+// Claims decodes the claims of the token verified by the JWT middleware
+// into a value of type T.
+//
+// Example:
+//
+//	claims, err := mows.Claims[UserClaims](c)
+//	if err != nil {
+//	    return err
+//	}
+//	if claims.Role != "admin" { ... }
+func Claims[T any](c *Context) (T, error) {
+	var claims T
+
+	token, ok := c.keys[JWTKey].(*Token)
+	if !ok {
+		return claims, NewHTTPError(http.StatusUnauthorized, "")
+	}
+	if err := token.Decode(&claims); err != nil {
+		return claims, err
+	}
+	return claims, nil
+}
+
+// JWTConfig defines the configuration for the JWT middleware.
+type JWTConfig struct {
+	// Keys provides the verification keys. It is required.
+	// Use StaticKeySet or NewJWKSKeySet.
+	Keys KeySet
+
+	// Algorithms lists the accepted signing algorithms.
+	// Defaults to HS256, RS256, ES256 and EdDSA. "none" is never accepted.
+	Algorithms []string
+
+	// Issuer, when set, must match the "iss" claim.
+	Issuer string
+
+	// Audience, when set, must be one of the "aud" claim values.
+	Audience string
+
+	// ClockSkew is the tolerance applied to "exp", "nbf" and "iat".
+	ClockSkew time.Duration
+
+	// Realm is sent in the WWW-Authenticate header.
+	// Defaults to "Restricted".
+	Realm string
+
+	// Header is the request header holding the token.
+	// Defaults to "Authorization".
+	Header string
+
+	// Scheme is the prefix expected before the token in Header.
+	// Defaults to "Bearer". Set it to "-" for headers that carry
+	// the bare token.
+	Scheme string
+
+	// Query, when set, is a query parameter checked after Header.
+	Query string
+
+	// Cookie, when set, is a cookie checked after Query.
+	Cookie string
+
+	// Principal resolves the principal stored under PrincipalKey.
+	// Defaults to the verified *Token.
+	Principal func(c *Context, token *Token) (any, error)
+}
+
+// JWT returns a middleware that authenticates requests with a signed
+// JSON Web Token.
+//
+// The token is read like in BearerAuth and its signature is verified
+// with a key from Keys, chosen by the "kid" header. The algorithm must
+// match the key type, so an HMAC secret can never be confused with a
+// public key. "exp", "nbf" and "iat" are checked with ClockSkew, and
+// "iss" and "aud" when Issuer and Audience are set.
+//
+// Invalid tokens are rejected with 401 Unauthorized; the HTTPError
+// wraps one of the ErrToken errors. The verified *Token is stored
+// under JWTKey and its claims can be read with Claims.
+//
+// Example:
+//
+//	api := app.Group("/api", mows.JWT(mows.JWTConfig{
+//	    Keys:     mows.StaticKeySet{"v1": secret},
+//	    Issuer:   "https://auth.example.com",
+//	    Audience: "orders",
+//	}))
+func JWT(config JWTConfig) Middleware {
+	if config.Keys == nil {
+		panic("mows: jwt requires a key set")
+	}
+	if len(config.Algorithms) == 0 {
+		config.Algorithms = []string{HS256, RS256, ES256, EdDSA}
+	}
+	if config.Realm == "" {
+		config.Realm = "Restricted"
+	}
+	lookup := newTokenLookup(config.Header, config.Scheme, config.Query, config.Cookie)
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			raw := lookup.token(c)
+			if raw == "" {
+				c.Writer.Header().Set("WWW-Authenticate", fmt.Sprintf("Bearer realm=%q", config.Realm))
+				return NewHTTPError(http.StatusUnauthorized, "")
+			}
+
+			token, err := config.verify(c, raw)
+			if err != nil {
+				var httpErr *HTTPError
+				if errors.As(err, &httpErr) {
+					return err
+				}
+				c.Writer.Header().Set("WWW-Authenticate", fmt.Sprintf("Bearer realm=%q, error=\"invalid_token\", error_description=%q", config.Realm, err.Error()))
+				return &HTTPError{Code: http.StatusUnauthorized, Message: "invalid token", Err: err}
+			}
+
+			var principal any = token
+			if config.Principal != nil {
+				if principal, err = config.Principal(c, token); err != nil {
+					return err
+				}
+			}
+
+			c.Set(JWTKey, token)
+			c.Set(PrincipalKey, principal)
+			return next(c)
+		}
+	}
+}
+
+// verify parses raw and checks its signature and claims.
+func (config *JWTConfig) verify(c *Context, raw string) (*Token, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, ErrTokenMalformed
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrTokenMalformed
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrTokenMalformed
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrTokenMalformed
+	}
+
+	token := &Token{Raw: raw, payload: payload}
+	if err := json.Unmarshal(headerJSON, &token.Header); err != nil {
+		return nil, ErrTokenMalformed
+	}
+	if !slices.Contains(config.Algorithms, token.Header.Algorithm) {
+		return nil, ErrTokenAlgorithm
+	}
+
+	key, err := config.Keys.Key(c.Request.Context(), token.Header.KeyID)
+	if err != nil {
+		if errors.Is(err, ErrTokenUnknownKey) {
+			return nil, ErrTokenUnknownKey
+		}
+		return nil, &HTTPError{Code: http.StatusServiceUnavailable, Message: "signing keys unavailable", Err: err}
+	}
+
+	signingInput := raw[:len(parts[0])+1+len(parts[1])]
+	if err := verifySignature(token.Header.Algorithm, publicKey(key), []byte(signingInput), signature); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(payload, &token.Claims); err != nil {
+		return nil, ErrTokenMalformed
+	}
+
+	if err := config.validateClaims(&token.Claims, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// validateClaims checks the time based and identity claims.
+func (config *JWTConfig) validateClaims(claims *RegisteredClaims, now time.Time) error {
+	skew := config.ClockSkew
+
+	if claims.ExpiresAt != nil && !now.Before(claims.ExpiresAt.Add(skew)) {
+		return ErrTokenExpired
+	}
+	if claims.NotBefore != nil && now.Add(skew).Before(claims.NotBefore.Time) {
+		return ErrTokenNotYetValid
+	}
+	if claims.IssuedAt != nil && now.Add(skew).Before(claims.IssuedAt.Time) {
+		return ErrTokenIssuedInFuture
+	}
+	if config.Issuer != "" && claims.Issuer != config.Issuer {
+		return ErrTokenIssuer
+	}
+	if config.Audience != "" && !slices.Contains(claims.Audience, config.Audience) {
+		return ErrTokenAudience
+	}
+	return nil
+}
+
+// verifySignature checks signature for alg, requiring the matching key type.
+func verifySignature(alg string, key any, input, signature []byte) error {
+	digest := sha256.Sum256(input)
+
+	switch alg {
+	case HS256:
+		secret, ok := key.([]byte)
+		if !ok {
+			return ErrTokenAlgorithm
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(input)
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return ErrTokenSignature
+		}
+
+	case RS256:
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return ErrTokenAlgorithm
+		}
+		if rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], signature) != nil {
+			return ErrTokenSignature
+		}
+
+	case ES256:
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok || pub.Curve != elliptic.P256() {
+			return ErrTokenAlgorithm
+		}
+		if len(signature) != 64 {
+			return ErrTokenSignature
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(pub, digest[:], r, s) {
+			return ErrTokenSignature
+		}
+
+	case EdDSA:
+		pub, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return ErrTokenAlgorithm
+		}
+		if !ed25519.Verify(pub, input, signature) {
+			return ErrTokenSignature
+		}
+
+	default:
+		return ErrTokenAlgorithm
+	}
+
+	return nil
+}
+
+// publicKey returns the verification key for key, converting private
+// keys to their public half and strings to HMAC secrets.
+func publicKey(key any) any {
+	switch k := key.(type) {
+	case string:
+		return []byte(k)
+	case *rsa.PrivateKey:
+		return &k.PublicKey
+	case *ecdsa.PrivateKey:
+		return &k.PublicKey
+	case ed25519.PrivateKey:
+		return k.Public()
+	default:
+		return key
+	}
+}
+
+// JWTSigner issues signed tokens, e.g. in login handlers and tests.
+type JWTSigner struct {
+	alg string
+	kid string
+	key any
+}
+
+// NewJWTSigner creates a signer for alg.
+//
+// key must be a []byte secret for HS256, an *rsa.PrivateKey for RS256,
+// an *ecdsa.PrivateKey on P-256 for ES256 or an ed25519.PrivateKey for
+// EdDSA. kid, when not empty, is written to the token header.
+//
+// Example:
+//
+//	signer, _ := mows.NewJWTSigner(mows.HS256, "v1", secret)
+//	token, _ := signer.Sign(UserClaims{
+//	    RegisteredClaims: mows.RegisteredClaims{
+//	        Subject:   user.ID,
+//	        ExpiresAt: mows.NewNumericDate(time.Now().Add(time.Hour)),
+//	    },
+//	    Role: user.Role,
+//	})
+func NewJWTSigner(alg, kid string, key any) (*JWTSigner, error) {
+	var ok bool
+	switch alg {
+	case HS256:
+		var secret []byte
+		secret, ok = publicKey(key).([]byte)
+		ok = ok && len(secret) > 0
+		key = secret
+	case RS256:
+		_, ok = key.(*rsa.PrivateKey)
+	case ES256:
+		var priv *ecdsa.PrivateKey
+		priv, ok = key.(*ecdsa.PrivateKey)
+		ok = ok && priv.Curve == elliptic.P256()
+	case EdDSA:
+		_, ok = key.(ed25519.PrivateKey)
+	default:
+		return nil, fmt.Errorf("mows: unsupported jwt algorithm %q", alg)
+	}
+	if !ok {
+		return nil, fmt.Errorf("mows: invalid %s signing key %T", alg, key)
+	}
+
+	return &JWTSigner{alg: alg, kid: kid, key: key}, nil
+}
+
+// Sign encodes claims as JSON and returns the signed token.
+func (s *JWTSigner) Sign(claims any) (string, error) {
+	header, err := json.Marshal(JWTHeader{Algorithm: s.alg, Type: "JWT", KeyID: s.kid})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(input))
+
+	var signature []byte
+	switch key := s.key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(input))
+		signature = mac.Sum(nil)
+
+	case *rsa.PrivateKey:
+		signature, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+
+	case *ecdsa.PrivateKey:
+		var r, sv *big.Int
+		r, sv, err = ecdsa.Sign(rand.Reader, key, digest[:])
+		if err == nil {
+			signature = make([]byte, 64)
+			r.FillBytes(signature[:32])
+			sv.FillBytes(signature[32:])
+		}
+
+	case ed25519.PrivateKey:
+		signature = ed25519.Sign(key, []byte(input))
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// JSONWebKey returns the public key of the signer as a JWK, for
+// publishing in a JWKS document. It returns false for HS256, whose
+// secret must not be published.
+func (s *JWTSigner) JSONWebKey() (JSONWebKey, bool) {
+	jwk, ok := newJSONWebKey(publicKey(s.key))
+	if !ok {
+		return JSONWebKey{}, false
+	}
+	jwk.KeyID = s.kid
+	jwk.Algorithm = s.alg
+	jwk.Use = "sig"
+	return jwk, true
+}
@@ -0,0 +1,237 @@
+package mows
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// HookFunc is a lifecycle hook registered with OnStart or OnShutdown.
+//
+// The context is cancelled when the hook exceeds its timeout.
+type HookFunc func(ctx context.Context) error
+
+// ShutdownConfig controls how the engine starts and stops.
+type ShutdownConfig struct {
+	// DrainTimeout is how long in-flight requests may take to finish
+	// during shutdown. Defaults to 5 seconds.
+	DrainTimeout time.Duration
+
+	// DrainDelay keeps the listener open after the engine starts
+	// draining, giving load balancers time to observe the readiness
+	// change. Defaults to 0.
+	DrainDelay time.Duration
+
+	// HookTimeout is the default timeout of each lifecycle hook.
+	// Defaults to 5 seconds.
+	HookTimeout time.Duration
+
+	// Signals trigger a graceful shutdown.
+	// Defaults to os.Interrupt and SIGTERM.
+	Signals []os.Signal
+
+	// DisableSignals stops Run from installing signal handlers, for
+	// applications that manage shutdown themselves via Engine.Shutdown.
+	DisableSignals bool
+
+	// GracefulRestart enables zero-downtime restarts: on SIGHUP or
+	// SIGUSR2 the engine starts a new copy of the process that inherits
+	// the listener, waits for it to become ready, then drains itself.
+	GracefulRestart bool
+
+	// RestartTimeout is how long to wait for the new process to become
+	// ready during a graceful restart. Defaults to 30 seconds.
+	RestartTimeout time.Duration
+}
+
+// DefaultShutdownConfig returns the ShutdownConfig used by New.
+func DefaultShutdownConfig() ShutdownConfig {
+	return ShutdownConfig{
+		DrainTimeout:   5 * time.Second,
+		HookTimeout:    5 * time.Second,
+		RestartTimeout: 30 * time.Second,
+		Signals:        []os.Signal{os.Interrupt, syscall.SIGTERM},
+	}
+}
+
+// WithShutdownConfig sets the lifecycle settings of the engine.
+//
+// Zero fields fall back to DefaultShutdownConfig.
+func WithShutdownConfig(config ShutdownConfig) Option {
+	return func(e *Engine) {
+		def := DefaultShutdownConfig()
+		if config.DrainTimeout <= 0 {
+			config.DrainTimeout = def.DrainTimeout
+		}
+		if config.HookTimeout <= 0 {
+			config.HookTimeout = def.HookTimeout
+		}
+		if config.RestartTimeout <= 0 {
+			config.RestartTimeout = def.RestartTimeout
+		}
+		if len(config.Signals) == 0 {
+			config.Signals = def.Signals
+		}
+		e.lifecycle.config = config
+	}
+}
+
+// lifecycle holds the hooks and shutdown state of an Engine.
+type lifecycle struct {
+	config        ShutdownConfig
+	startHooks    []hook
+	shutdownHooks []hook
+
+	draining     atomic.Bool
+	handedOff    atomic.Bool
+	shutdownOnce sync.Once
+	shutdownErr  error
+	inherited    int
+}
+
+// hook is a registered HookFunc with its timeout.
+type hook struct {
+	fn      HookFunc
+	timeout time.Duration
+}
+
+// OnStart registers a hook that runs before the server starts accepting
+// connections. Hooks run in registration order; if one fails, Run
+// returns its error without serving.
+func (e *Engine) OnStart(fn HookFunc) {
+	e.lifecycle.startHooks = append(e.lifecycle.startHooks, hook{fn: fn})
+}
+
+// OnShutdown registers a hook that runs after in-flight requests have
+// drained, e.g. to close database pools or flush queues.
+//
+// Hooks run in registration order, each with the configured HookTimeout.
+// All hooks run even if some fail; their errors are joined.
+func (e *Engine) OnShutdown(fn HookFunc) {
+	e.lifecycle.shutdownHooks = append(e.lifecycle.shutdownHooks, hook{fn: fn})
+}
+
+// OnShutdownTimeout registers a shutdown hook with its own timeout.
+func (e *Engine) OnShutdownTimeout(timeout time.Duration, fn HookFunc) {
+	e.lifecycle.shutdownHooks = append(e.lifecycle.shutdownHooks, hook{fn: fn, timeout: timeout})
+}
+
+// Ready reports whether the engine accepts new work.
+//
+// It returns false once shutdown has started.
+func (e *Engine) Ready() bool {
+	return !e.lifecycle.draining.Load()
+}
+
+// Readiness returns a handler for readiness probes.
+//
+// It responds 200 while the engine is ready and 503 once it is draining.
+//
+// Example:
+//
+//	app.GET("/readyz", app.Readiness())
+func (e *Engine) Readiness() HandlerFunc {
+	return func(c *Context) error {
+		if !e.Ready() {
+			return c.JSON(http.StatusServiceUnavailable, map[string]string{"status": "draining"})
+		}
+		return c.JSON(http.StatusOK, map[string]string{"status": "ok"})
+	}
+}
+
+// Shutdown gracefully stops the engine.
+//
+// It performs the following steps:
+//
+//  1. Marks the engine as draining, so Ready reports false.
+//  2. Waits for DrainDelay.
+//  3. Closes the listener and waits up to DrainTimeout for in-flight requests.
+//  4. Runs the OnShutdown hooks in order.
+//
+// Shutdown is safe to call more than once and from tests; later calls
+// wait for the first one and return its result. Run returns once
+// Shutdown completes.
+func (e *Engine) Shutdown(ctx context.Context) error {
+	e.lifecycle.shutdownOnce.Do(func() {
+		e.lifecycle.shutdownErr = e.shutdown(ctx)
+	})
+	return e.lifecycle.shutdownErr
+}
+
+// shutdown implements Shutdown.
+func (e *Engine) shutdown(ctx context.Context) error {
+	cfg := e.lifecycle.config
+	e.lifecycle.draining.Store(true)
+
+	if cfg.DrainDelay > 0 {
+		select {
+		case <-time.After(cfg.DrainDelay):
+		case <-ctx.Done():
+		}
+	}
+
+	var errs []error
+
+	if e.server != nil {
+		drainCtx, cancel := context.WithTimeout(ctx, cfg.DrainTimeout)
+		defer cancel()
+
+		if err := e.server.Shutdown(drainCtx); err != nil {
+			e.logger.Error("graceful shutdown failed", "error", err)
+			errs = append(errs, err)
+		}
+	}
+
+	for i, h := range e.lifecycle.shutdownHooks {
+		if err := e.runHook(ctx, h); err != nil {
+			errs = append(errs, fmt.Errorf("shutdown hook %d: %w", i, err))
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	e.logger.Info("server stopped gracefully")
+	return nil
+}
+
+// runStartHooks runs the OnStart hooks in order, stopping at the first error.
+func (e *Engine) runStartHooks() error {
+	for i, h := range e.lifecycle.startHooks {
+		if err := e.runHook(context.Background(), h); err != nil {
+			return fmt.Errorf("start hook %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// runHook runs a single hook and gives up once its timeout expires,
+// even if the hook ignores its context.
+func (e *Engine) runHook(ctx context.Context, h hook) error {
+	timeout := h.timeout
+	if timeout <= 0 {
+		timeout = e.lifecycle.config.HookTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- h.fn(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
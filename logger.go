@@ -2,44 +2,113 @@ package mows
 
 import (
 	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"sync"
 	"time"
 )
 
-// Logger returns a middleware that logs HTTP requests.
+// LoggerConfig defines the configuration for the Logger middleware.
+type LoggerConfig struct {
+	// Output receives the log lines. Defaults to os.Stdout.
+	Output io.Writer
+
+	// Sample maps route patterns to the fraction of requests that are
+	// logged, between 0 and 1. Routes not listed are always logged.
+	// Failed requests (errors or 5xx) are always logged.
+	//
+	// Example: {"/metrics": 0.01} logs one in a hundred scrapes.
+	Sample map[string]float64
+}
+
+// Logger returns a middleware that logs HTTP requests to stdout.
 //
 // Logged information includes:
 //
 //   - Status code
 //   - Request latency
 //   - HTTP method
-//   - Request path
+//   - Request path and route pattern
 //   - Client IP
+//   - Request and response size
+//   - User agent
+//   - Request ID, when the RequestID middleware is used
+//   - Error message, if the request failed
 func Logger() Middleware {
+	return LoggerWithConfig(LoggerConfig{})
+}
+
+// LoggerWithConfig returns a Logger middleware with custom settings.
+//
+// Errors returned by handlers are passed to the error handler before
+// the line is written, so the logged status is the one sent to the client.
+func LoggerWithConfig(config LoggerConfig) Middleware {
+	if config.Output == nil {
+		config.Output = os.Stdout
+	}
+
+	var mu sync.Mutex
+
 	return func(next HandlerFunc) HandlerFunc {
 		return func(c *Context) error {
 			start := time.Now()
 
+			var in countingReader
+			if c.Request.Body != nil && c.Request.Body != http.NoBody {
+				in.ReadCloser = c.Request.Body
+				c.Request.Body = &in
+			}
+
 			err := next(c)
 			if err != nil {
-				return err
+				c.handleError(err)
 			}
 
-			latency := time.Since(start)
-			method := c.Request.Method
-			path := c.Request.URL.Path
 			status := c.Writer.status
-			ip := c.Request.RemoteAddr
+			failed := err != nil || status >= 500
+			if rate, ok := config.Sample[c.Route()]; ok && !failed && rand.Float64() >= rate {
+				return err
+			}
 
-			fmt.Printf(
-				"[%s] %d | %v | %s %s | %s\n",
-				time.Now().Format("2006-01-02 15:04:05"),
+			line := fmt.Sprintf(
+				"[%s] %d | %v | %s %s (%s) | %s | in=%dB out=%dB | %q",
+				start.Format("2006-01-02 15:04:05"),
 				status,
-				latency,
-				method,
-				path,
-				ip,
+				time.Since(start),
+				c.Request.Method,
+				c.Request.URL.Path,
+				c.Route(),
+				c.ClientIP(),
+				in.n,
+				c.Writer.size,
+				c.Request.UserAgent(),
 			)
-			return nil
+			if id := c.RequestID(); id != "" {
+				line += " | id=" + id
+			}
+			if err != nil {
+				line += " | error: " + err.Error()
+			}
+
+			mu.Lock()
+			fmt.Fprintln(config.Output, line)
+			mu.Unlock()
+
+			return err
 		}
 	}
 }
+
+// countingReader counts the bytes read from a request body.
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	return n, err
+}
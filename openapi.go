@@ -0,0 +1,634 @@
+package mows
+
+import (
+	"encoding"
+	"encoding/json"
+	"net/http"
+	"path"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OpenAPIConfig defines how the OpenAPI document is described and served.
+type OpenAPIConfig struct {
+	// Path serves the document as JSON. Defaults to "/openapi.json".
+	Path string
+
+	// Title of the API. Defaults to "API".
+	Title string
+
+	// Version of the API. Defaults to "1.0.0".
+	Version string
+
+	// Description of the API. CommonMark is allowed.
+	Description string
+
+	// Servers lists the base URLs of the API.
+	Servers []OpenAPIServer
+}
+
+func (config OpenAPIConfig) withDefaults() OpenAPIConfig {
+	if config.Path == "" {
+		config.Path = "/openapi.json"
+	}
+	if config.Title == "" {
+		config.Title = "API"
+	}
+	if config.Version == "" {
+		config.Version = "1.0.0"
+	}
+	return config
+}
+
+// OpenAPIDocument is an OpenAPI 3.1 document.
+type OpenAPIDocument struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       OpenAPIInfo                             `json:"info"`
+	Servers    []OpenAPIServer                         `json:"servers,omitempty"`
+	Paths      map[string]map[string]*OpenAPIOperation `json:"paths"`
+	Components OpenAPIComponents                       `json:"components,omitzero"`
+}
+
+// OpenAPIInfo describes the API.
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// OpenAPIServer is a base URL of the API.
+type OpenAPIServer struct {
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+// OpenAPIOperation describes a route.
+type OpenAPIOperation struct {
+	OperationID string                      `json:"operationId,omitempty"`
+	Summary     string                      `json:"summary,omitempty"`
+	Description string                      `json:"description,omitempty"`
+	Tags        []string                    `json:"tags,omitempty"`
+	Parameters  []*OpenAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*OpenAPIResponse `json:"responses"`
+	Deprecated  bool                        `json:"deprecated,omitempty"`
+}
+
+// OpenAPIParameter describes a path, query or header parameter.
+type OpenAPIParameter struct {
+	Name        string      `json:"name"`
+	In          string      `json:"in"`
+	Description string      `json:"description,omitempty"`
+	Required    bool        `json:"required,omitempty"`
+	Schema      *JSONSchema `json:"schema"`
+}
+
+// OpenAPIRequestBody describes a request body.
+type OpenAPIRequestBody struct {
+	Required bool                        `json:"required,omitempty"`
+	Content  map[string]OpenAPIMediaType `json:"content"`
+}
+
+// OpenAPIResponse describes a response.
+type OpenAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]OpenAPIMediaType `json:"content,omitempty"`
+}
+
+// OpenAPIMediaType holds the schema of a body.
+type OpenAPIMediaType struct {
+	Schema *JSONSchema `json:"schema"`
+}
+
+// OpenAPIComponents holds the schemas referenced by the document.
+type OpenAPIComponents struct {
+	Schemas map[string]*JSONSchema `json:"schemas,omitempty"`
+}
+
+// JSONSchema is the subset of JSON Schema 2020-12 generated from Go types.
+type JSONSchema struct {
+	Ref                  string                 `json:"$ref,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	AdditionalProperties *JSONSchema            `json:"additionalProperties,omitempty"`
+	Enum                 []any                  `json:"enum,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty"`
+	Maximum              *float64               `json:"maximum,omitempty"`
+	ExclusiveMinimum     *float64               `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     *float64               `json:"exclusiveMaximum,omitempty"`
+	MinLength            *int                   `json:"minLength,omitempty"`
+	MaxLength            *int                   `json:"maxLength,omitempty"`
+	MinItems             *int                   `json:"minItems,omitempty"`
+	MaxItems             *int                   `json:"maxItems,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+}
+
+// ServeOpenAPI serves the OpenAPI document of the engine at config.Path.
+//
+// The document is generated from the registered routes and the
+// documentation attached to them (see Route).
+//
+// Example:
+//
+//	app.ServeOpenAPI(mows.OpenAPIConfig{
+//	    Title:   "Orders API",
+//	    Version: "2.3.0",
+//	})
+func (e *Engine) ServeOpenAPI(config OpenAPIConfig) {
+	e.openapi = config.withDefaults()
+	e.GET(e.openapi.Path, func(c *Context) error {
+		return c.JSON(http.StatusOK, e.OpenAPI())
+	}).Hidden()
+}
+
+// OpenAPI generates the OpenAPI 3.1 document of the registered routes,
+// e.g. to export it at build time:
+//
+//	data, _ := json.MarshalIndent(app.OpenAPI(), "", "  ")
+//	os.WriteFile("openapi.json", data, 0644)
+//
+// Routes with a "*name" segment, such as the one registered by Static,
+// are not included: the router matches such segments literally, so
+// they do not describe the paths they serve.
+func (e *Engine) OpenAPI() *OpenAPIDocument {
+	config := e.openapi.withDefaults()
+	doc := &OpenAPIDocument{
+		OpenAPI: "3.1.0",
+		Info: OpenAPIInfo{
+			Title:       config.Title,
+			Version:     config.Version,
+			Description: config.Description,
+		},
+		Servers: config.Servers,
+		Paths:   make(map[string]map[string]*OpenAPIOperation),
+	}
+
+	g := &schemaGenerator{
+		schemas: make(map[string]*JSONSchema),
+		names:   make(map[reflect.Type]string),
+	}
+	e.router.each(func(method string, rt *route) {
+		if rt.meta.Hidden || hasWildcard(rt.pattern) {
+			return
+		}
+
+		p := openAPIPath(rt.pattern)
+		if doc.Paths[p] == nil {
+			doc.Paths[p] = make(map[string]*OpenAPIOperation)
+		}
+		doc.Paths[p][strings.ToLower(method)] = g.operation(method, rt)
+	})
+	doc.Components.Schemas = g.schemas
+
+	return doc
+}
+
+// hasWildcard reports whether pattern has a "*name" segment.
+func hasWildcard(pattern string) bool {
+	for _, segment := range strings.Split(pattern, "/") {
+		if strings.HasPrefix(segment, "*") {
+			return true
+		}
+	}
+	return false
+}
+
+// openAPIPath converts "/users/:id" to "/users/{id}".
+func openAPIPath(pattern string) string {
+	parts := strings.Split(pattern, "/")
+	for i, part := range parts {
+		if name, ok := strings.CutPrefix(part, ":"); ok {
+			parts[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// schemaGenerator builds schemas, collecting named structs as components.
+type schemaGenerator struct {
+	schemas map[string]*JSONSchema
+	names   map[reflect.Type]string
+}
+
+// operation documents a route.
+func (g *schemaGenerator) operation(method string, rt *route) *OpenAPIOperation {
+	meta := rt.meta
+	op := &OpenAPIOperation{
+		OperationID: meta.OperationID,
+		Summary:     meta.Summary,
+		Description: meta.Description,
+		Tags:        meta.Tags,
+		Deprecated:  meta.Deprecated,
+		Responses:   make(map[string]*OpenAPIResponse),
+	}
+
+	var body *JSONSchema
+	if meta.Request != nil {
+		op.Parameters, body = g.request(meta.Request)
+	}
+
+	for _, part := range strings.Split(rt.pattern, "/") {
+		if name, ok := strings.CutPrefix(part, ":"); ok && findParameter(op.Parameters, "path", name) == nil {
+			op.Parameters = append(op.Parameters, &OpenAPIParameter{
+				Name:     name,
+				In:       "path",
+				Required: true,
+				Schema:   &JSONSchema{Type: "string"},
+			})
+		}
+	}
+	for _, param := range meta.Params {
+		if p := findParameter(op.Parameters, param.In, param.Name); p != nil {
+			p.Description = param.Description
+			continue
+		}
+		op.Parameters = append(op.Parameters, &OpenAPIParameter{
+			Name:        param.Name,
+			In:          param.In,
+			Description: param.Description,
+			Required:    param.In == "path",
+			Schema:      &JSONSchema{Type: "string"},
+		})
+	}
+
+	if body != nil && method != http.MethodGet && method != http.MethodHead && method != http.MethodDelete {
+		op.RequestBody = &OpenAPIRequestBody{
+			Required: true,
+			Content:  map[string]OpenAPIMediaType{"application/json": {Schema: body}},
+		}
+	}
+
+	for _, resp := range meta.Responses {
+		description := http.StatusText(resp.Status)
+		if description == "" {
+			description = "Response"
+		}
+		r := &OpenAPIResponse{Description: description}
+		if resp.Type != nil {
+			r.Content = map[string]OpenAPIMediaType{"application/json": {Schema: g.schema(resp.Type)}}
+		}
+		op.Responses[strconv.Itoa(resp.Status)] = r
+	}
+	if len(op.Responses) == 0 {
+		op.Responses["200"] = &OpenAPIResponse{Description: "OK"}
+	}
+
+	return op
+}
+
+func findParameter(params []*OpenAPIParameter, in, name string) *OpenAPIParameter {
+	for _, p := range params {
+		if p.In == in && p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// request splits a request struct into parameters and a body schema.
+func (g *schemaGenerator) request(t reflect.Type) ([]*OpenAPIParameter, *JSONSchema) {
+	t = indirectType(t)
+	if t.Kind() != reflect.Struct {
+		return nil, g.schema(t)
+	}
+
+	var params []*OpenAPIParameter
+	var bodyFields []reflect.StructField
+	for _, f := range structFields(t) {
+		in, name := paramTag(f)
+		if in == "" {
+			bodyFields = append(bodyFields, f)
+			continue
+		}
+		schema, required := g.field(f)
+		params = append(params, &OpenAPIParameter{
+			Name:     name,
+			In:       in,
+			Required: required || in == "path",
+			Schema:   schema,
+		})
+	}
+
+	switch {
+	case len(params) == 0:
+		return nil, g.schema(t)
+	case len(bodyFields) == 0:
+		return params, nil
+	default:
+		return params, g.object(bodyFields)
+	}
+}
+
+var (
+	timeType          = reflect.TypeFor[time.Time]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// schema returns the schema of a type. Named structs are referenced
+// from the components.
+func (g *schemaGenerator) schema(t reflect.Type) *JSONSchema {
+	t = indirectType(t)
+
+	switch {
+	case t == timeType:
+		return &JSONSchema{Type: "string", Format: "date-time"}
+	case implements(t, jsonMarshalerType):
+		return &JSONSchema{}
+	case implements(t, textMarshalerType):
+		return &JSONSchema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}
+	case reflect.Int32:
+		return &JSONSchema{Type: "integer", Format: "int32"}
+	case reflect.Int64:
+		return &JSONSchema{Type: "integer", Format: "int64"}
+	case reflect.Int, reflect.Int8, reflect.Int16,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &JSONSchema{Type: "integer"}
+	case reflect.Float32:
+		return &JSONSchema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &JSONSchema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &JSONSchema{Type: "string"}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &JSONSchema{Type: "string", Format: "byte"}
+		}
+		return &JSONSchema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Array:
+		n := t.Len()
+		return &JSONSchema{Type: "array", Items: g.schema(t.Elem()), MinItems: &n, MaxItems: &n}
+	case reflect.Map:
+		return &JSONSchema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(structFields(t))
+		}
+		return g.ref(t)
+	}
+
+	// interfaces and other types accept any value
+	return &JSONSchema{}
+}
+
+// ref returns a reference to the component of a named struct.
+func (g *schemaGenerator) ref(t reflect.Type) *JSONSchema {
+	name, ok := g.names[t]
+	if !ok {
+		name = componentName(t)
+		if _, taken := g.schemas[name]; taken {
+			name = path.Base(t.PkgPath()) + "." + name
+		}
+		// types of the same name in packages of the same name
+		for i, base := 2, name; ; i++ {
+			if _, taken := g.schemas[name]; !taken {
+				break
+			}
+			name = base + strconv.Itoa(i)
+		}
+
+		// register before building so recursive types terminate
+		g.names[t] = name
+		g.schemas[name] = nil
+		g.schemas[name] = g.object(structFields(t))
+	}
+	return &JSONSchema{Ref: "#/components/schemas/" + name}
+}
+
+// object returns the schema of a struct with the given fields.
+func (g *schemaGenerator) object(fields []reflect.StructField) *JSONSchema {
+	s := &JSONSchema{Type: "object", Properties: make(map[string]*JSONSchema)}
+	for _, f := range fields {
+		name, ok := jsonName(f)
+		if !ok {
+			continue
+		}
+		prop, required := g.field(f)
+		s.Properties[name] = prop
+		if required {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return s
+}
+
+// field returns the schema of a struct field, with the constraints of
+// its validate tag, and whether it is required.
+func (g *schemaGenerator) field(f reflect.StructField) (*JSONSchema, bool) {
+	s := g.schema(f.Type)
+	return s, applyValidate(s, f.Type, f.Tag.Get("validate"))
+}
+
+// applyValidate converts validate rules into schema constraints and
+// reports whether the value is required. Rules after "dive" apply to
+// the elements of a slice or map.
+func applyValidate(s *JSONSchema, t reflect.Type, tag string) bool {
+	if tag == "" || tag == "-" {
+		return false
+	}
+
+	t = indirectType(t)
+	required := false
+	rules := strings.Split(tag, ",")
+	for i, rule := range rules {
+		key, value, _ := strings.Cut(rule, "=")
+		switch key {
+		case "required":
+			required = true
+		case "dive":
+			elem := s.Items
+			if elem == nil {
+				elem = s.AdditionalProperties
+			}
+			if elem != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map) {
+				applyValidate(elem, t.Elem(), strings.Join(rules[i+1:], ","))
+			}
+			return required
+		case "min", "gte":
+			setBound(s, t, value, false, false)
+		case "max", "lte":
+			setBound(s, t, value, true, false)
+		case "gt":
+			setBound(s, t, value, false, true)
+		case "lt":
+			setBound(s, t, value, true, true)
+		case "len":
+			setBound(s, t, value, false, false)
+			setBound(s, t, value, true, false)
+		case "oneof":
+			for _, option := range strings.Fields(value) {
+				s.Enum = append(s.Enum, enumValue(t, option))
+			}
+		case "email", "uuid", "hostname", "ipv4", "ipv6":
+			s.Format = key
+		case "uuid4":
+			s.Format = "uuid"
+		case "url", "uri":
+			s.Format = "uri"
+		case "alpha":
+			s.Pattern = "^[a-zA-Z]+$"
+		case "alphanum":
+			s.Pattern = "^[a-zA-Z0-9]+$"
+		case "numeric":
+			s.Pattern = "^[-+]?[0-9]+(?:\\.[0-9]+)?$"
+		}
+	}
+	return required
+}
+
+// setBound sets a minimum or maximum on the value, length or item count,
+// depending on the type.
+func setBound(s *JSONSchema, t reflect.Type, value string, upper, exclusive bool) {
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		count := int(n)
+		if exclusive && upper {
+			count--
+		} else if exclusive {
+			count++
+		}
+		if t.Kind() == reflect.String {
+			setInt(&s.MinLength, &s.MaxLength, count, upper)
+		} else if t.Kind() != reflect.Map {
+			setInt(&s.MinItems, &s.MaxItems, count, upper)
+		}
+	case reflect.Struct:
+		// time.Time and other structs have no numeric bounds
+	default:
+		switch {
+		case upper && exclusive:
+			s.ExclusiveMaximum = &n
+		case upper:
+			s.Maximum = &n
+		case exclusive:
+			s.ExclusiveMinimum = &n
+		default:
+			s.Minimum = &n
+		}
+	}
+}
+
+func setInt(lower, upper **int, n int, isUpper bool) {
+	if isUpper {
+		*upper = &n
+	} else {
+		*lower = &n
+	}
+}
+
+// enumValue converts a oneof option to the JSON type of the field.
+func enumValue(t reflect.Type, option string) any {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n, err := strconv.ParseInt(option, 10, 64); err == nil {
+			return n
+		}
+	case reflect.Float32, reflect.Float64:
+		if n, err := strconv.ParseFloat(option, 64); err == nil {
+			return n
+		}
+	}
+	return option
+}
+
+// structFields returns the exported fields of a struct, including those
+// promoted from embedded structs. The Index of promoted fields is the
+// full path from t.
+func structFields(t reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if f.Anonymous && f.Tag.Get("json") == "" {
+			if ft := indirectType(f.Type); ft.Kind() == reflect.Struct {
+				for _, promoted := range structFields(ft) {
+					promoted.Index = append([]int{i}, promoted.Index...)
+					fields = append(fields, promoted)
+				}
+				continue
+			}
+		}
+		if f.IsExported() {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// jsonName returns the JSON name of a field, following encoding/json.
+func jsonName(f reflect.StructField) (string, bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	if name, _, _ := strings.Cut(tag, ","); name != "" {
+		return name, true
+	}
+	return f.Name, true
+}
+
+// paramTags maps struct tags to parameter locations.
+var paramTags = []struct{ tag, in string }{
+	{"uri", "path"},
+	{"query", "query"},
+	{"header", "header"},
+}
+
+// paramTag returns the location and name of a field bound from the path,
+// query or headers, or an empty location for body fields.
+func paramTag(f reflect.StructField) (in, name string) {
+	for _, p := range paramTags {
+		if value, ok := f.Tag.Lookup(p.tag); ok {
+			name, _, _ = strings.Cut(value, ",")
+			if name == "" {
+				name = f.Name
+			}
+			return p.in, name
+		}
+	}
+	return "", ""
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+func implements(t, iface reflect.Type) bool {
+	return t.Implements(iface) || reflect.PointerTo(t).Implements(iface)
+}
+
+// packagePath matches the import paths in generic type names, such as
+// "github.com/acme/api." in "Page[github.com/acme/api.User]".
+var packagePath = regexp.MustCompile(`[\w.\-/]*/`)
+
+// componentName returns a component name for a named type, e.g.
+// "Page_api.User" for Page[api.User].
+func componentName(t reflect.Type) string {
+	name := packagePath.ReplaceAllString(t.Name(), "")
+	name = strings.NewReplacer("[", "_", "]", "", ",", "_", "*", "", " ", "").Replace(name)
+	return strings.Map(func(r rune) rune {
+		if r == '.' || r == '_' || r == '-' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' {
+			return r
+		}
+		return '_'
+	}, name)
+}
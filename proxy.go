@@ -0,0 +1,325 @@
+package mows
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// Common platform headers carrying the client IP, for use with
+// Engine.SetTrustedPlatform.
+const (
+	PlatformCloudflare = "CF-Connecting-IP"
+	PlatformFastly     = "Fastly-Client-IP"
+	PlatformAkamai     = "True-Client-IP"
+	PlatformGoogleApp  = "X-Appengine-Remote-Addr"
+)
+
+// ProxyHeader selects the forwarding headers trusted proxies set, for
+// use with Engine.SetProxyHeader.
+type ProxyHeader int
+
+const (
+	// ProxyXForwarded reads X-Forwarded-For, X-Forwarded-Proto and
+	// X-Forwarded-Host. It is the default.
+	ProxyXForwarded ProxyHeader = iota
+
+	// ProxyForwarded reads the for, proto and host parameters of the
+	// RFC 7239 Forwarded header.
+	ProxyForwarded
+
+	// ProxyXRealIP reads the client IP from X-Real-IP. Scheme and Host
+	// use the connection.
+	ProxyXRealIP
+)
+
+// proxyConfig holds the trusted proxy settings of an Engine.
+type proxyConfig struct {
+	trusted  []netip.Prefix
+	platform string
+	header   ProxyHeader
+}
+
+// SetTrustedProxies sets the proxies whose forwarding headers are trusted.
+//
+// Entries may be CIDR ranges ("10.0.0.0/8", "fd00::/8") or single IPs.
+// By default no proxy is trusted, so ClientIP, Scheme and Host only use
+// the connection itself. Passing nil resets to that default.
+//
+// Example:
+//
+//	app.SetTrustedProxies([]string{"10.0.0.0/8", "127.0.0.1"})
+func (e *Engine) SetTrustedProxies(cidrs []string) error {
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		prefix, err := parsePrefix(cidr)
+		if err != nil {
+			return err
+		}
+		prefixes = append(prefixes, prefix)
+	}
+
+	e.proxy.trusted = prefixes
+	return nil
+}
+
+// SetTrustedPlatform sets a header, such as PlatformCloudflare, that
+// holds the client IP as set by a trusted edge platform.
+//
+// The header is only used when the request comes from a trusted proxy.
+// Once set, ClientIP reads only this header.
+func (e *Engine) SetTrustedPlatform(header string) {
+	e.proxy.platform = header
+}
+
+// SetProxyHeader sets which forwarding headers trusted proxies set.
+// Defaults to ProxyXForwarded.
+//
+// Only the selected headers are read, so a client cannot inject the
+// others through a proxy that passes them on unchanged.
+//
+// Example:
+//
+//	app.SetProxyHeader(mows.ProxyForwarded)
+func (e *Engine) SetProxyHeader(header ProxyHeader) {
+	e.proxy.header = header
+}
+
+// isTrusted reports whether addr is a trusted proxy.
+func (p *proxyConfig) isTrusted(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range p.trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// fromTrustedProxy reports whether the request's peer is a trusted proxy.
+func (c *Context) fromTrustedProxy() bool {
+	addr, err := netip.ParseAddr(remoteIP(c.Request))
+	return err == nil && c.engine.proxy.isTrusted(addr)
+}
+
+// ClientIP returns the IP address of the client.
+//
+// When the request comes from a trusted proxy (see SetTrustedProxies),
+// it is read from the trusted platform header if one is set (see
+// SetTrustedPlatform), or else from the header selected with
+// SetProxyHeader. No other header is consulted.
+//
+// Forwarding chains are walked from the right, skipping trusted hops,
+// so clients cannot spoof their address by sending these headers.
+// Otherwise the connection's remote address is returned.
+func (c *Context) ClientIP() string {
+	remote := remoteIP(c.Request)
+	if !c.fromTrustedProxy() {
+		return remote
+	}
+
+	h := c.Request.Header
+	proxy := &c.engine.proxy
+	if proxy.platform != "" {
+		if addr, ok := parseIP(h.Get(proxy.platform)); ok {
+			return addr.String()
+		}
+		return remote
+	}
+
+	var chain []string
+	switch proxy.header {
+	case ProxyForwarded:
+		for _, element := range forwardedElements(h.Values("Forwarded")) {
+			chain = append(chain, element["for"])
+		}
+	case ProxyXRealIP:
+		if addr, ok := parseIP(h.Get("X-Real-IP")); ok {
+			return addr.String()
+		}
+		return remote
+	default:
+		chain = splitValues(h.Values("X-Forwarded-For"))
+	}
+
+	if ip, ok := c.walkChain(chain); ok {
+		return ip
+	}
+	return remote
+}
+
+// walkChain returns the rightmost untrusted address of a forwarding
+// chain, or the leftmost one if every hop is trusted.
+func (c *Context) walkChain(chain []string) (string, bool) {
+	var leftmost netip.Addr
+	for i := len(chain) - 1; i >= 0; i-- {
+		addr, ok := parseIP(chain[i])
+		if !ok {
+			// a malformed hop cannot be trusted; stop here
+			return "", false
+		}
+		if !c.engine.proxy.isTrusted(addr) {
+			return addr.String(), true
+		}
+		leftmost = addr
+	}
+
+	if leftmost.IsValid() {
+		return leftmost.String(), true
+	}
+	return "", false
+}
+
+// Scheme returns the scheme the client used, "http" or "https".
+//
+// The proto of the header selected with SetProxyHeader is honored only
+// for requests from trusted proxies. Like ClientIP, the chain is walked right to
+// left, so entries the client prepended are ignored.
+func (c *Context) Scheme() string {
+	if proto := c.forwardedParam("proto", "X-Forwarded-Proto"); proto != "" {
+		return strings.ToLower(proto)
+	}
+
+	if c.Request.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// Host returns the host the client requested.
+//
+// The host of the header selected with SetProxyHeader is honored only
+// for requests from trusted proxies. Like ClientIP, the chain is walked right to
+// left, so entries the client prepended are ignored.
+func (c *Context) Host() string {
+	if host := c.forwardedParam("host", "X-Forwarded-Host"); host != "" {
+		return host
+	}
+	return c.Request.Host
+}
+
+// forwardedParam returns the trusted value of a Forwarded parameter, or
+// of the equivalent X-Forwarded-* header, whichever SetProxyHeader
+// selected. It is empty for requests from untrusted peers.
+func (c *Context) forwardedParam(param, header string) string {
+	if !c.fromTrustedProxy() {
+		return ""
+	}
+
+	h := c.Request.Header
+	switch c.engine.proxy.header {
+	case ProxyXForwarded:
+		values := splitValues(h.Values(header))
+		return c.forwardedValue(values, splitValues(h.Values("X-Forwarded-For")))
+	case ProxyForwarded:
+		elements := forwardedElements(h.Values("Forwarded"))
+		values := make([]string, len(elements))
+		chain := make([]string, len(elements))
+		for i, element := range elements {
+			values[i], chain[i] = element[param], element["for"]
+		}
+		return c.forwardedValue(values, chain)
+	}
+	return ""
+}
+
+// forwardedValue walks per-hop values right to left, as ClientIP walks
+// the forwarding chain, and returns the leftmost non-empty value added
+// by a trusted proxy.
+//
+// values[i] is the value appended by the proxy that received the
+// request from chain[i], aligned from the right. The rightmost value was
+// added by the direct peer, which is trusted. The walk stops after the
+// first hop whose address is untrusted: entries to its left were sent
+// by the client and may be spoofed.
+func (c *Context) forwardedValue(values, chain []string) string {
+	var value string
+	for k := 1; k <= len(values); k++ {
+		if v := strings.TrimSpace(values[len(values)-k]); v != "" {
+			value = v
+		}
+		if k > len(chain) {
+			break
+		}
+		addr, ok := parseIP(chain[len(chain)-k])
+		if !ok || !c.engine.proxy.isTrusted(addr) {
+			break
+		}
+	}
+	return value
+}
+
+// forwardedElements parses RFC 7239 Forwarded header values into a
+// list of elements, each a map of lower-cased parameter names to values.
+func forwardedElements(values []string) []map[string]string {
+	var elements []map[string]string
+	for _, value := range values {
+		for _, element := range strings.Split(value, ",") {
+			params := make(map[string]string)
+			for _, pair := range strings.Split(element, ";") {
+				key, val, ok := strings.Cut(strings.TrimSpace(pair), "=")
+				if !ok {
+					continue
+				}
+				params[strings.ToLower(key)] = strings.Trim(val, `"`)
+			}
+			elements = append(elements, params)
+		}
+	}
+	return elements
+}
+
+// parseIP parses an address as found in forwarding headers, accepting
+// optional ports and brackets, e.g. "[2001:db8::1]:4711".
+func parseIP(s string) (netip.Addr, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return netip.Addr{}, false
+	}
+
+	if addrPort, err := netip.ParseAddrPort(s); err == nil {
+		return addrPort.Addr().Unmap(), true
+	}
+
+	addr, err := netip.ParseAddr(strings.Trim(s, "[]"))
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+// parsePrefix parses a CIDR range or a single IP as a prefix.
+func parsePrefix(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		return prefix.Masked(), nil
+	}
+
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// splitValues splits comma-separated header values into entries.
+func splitValues(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	return strings.Split(strings.Join(values, ","), ",")
+}
+
+// remoteIP returns the host part of the request's remote address.
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
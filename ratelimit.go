@@ -0,0 +1,332 @@
+package mows
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimitAlgorithm selects how requests are counted.
+type RateLimitAlgorithm int
+
+const (
+	// TokenBucket refills Limit tokens per Window and allows bursts
+	// up to Burst requests.
+	TokenBucket RateLimitAlgorithm = iota
+
+	// SlidingWindow allows Limit requests in any rolling Window,
+	// weighting the previous window by how much of it still overlaps.
+	SlidingWindow
+)
+
+// RateLimitRule describes the limit applied to a single key.
+type RateLimitRule struct {
+	Algorithm RateLimitAlgorithm
+	Limit     int
+	Window    time.Duration
+	Burst     int
+}
+
+// RateLimitResult is the outcome of a single rate limit check.
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// LimiterStore keeps rate limit state per key.
+//
+// The default store is in-memory (see NewMemoryLimiterStore). Stores
+// backed by shared storage allow limits across multiple instances.
+type LimiterStore interface {
+	// Take consumes one request for key and reports whether it is allowed.
+	Take(key string, rule RateLimitRule, now time.Time) (RateLimitResult, error)
+}
+
+// RateLimitConfig defines the configuration for the RateLimit middleware.
+type RateLimitConfig struct {
+	// Algorithm selects TokenBucket (default) or SlidingWindow.
+	Algorithm RateLimitAlgorithm
+
+	// Limit is the number of requests allowed per Window.
+	Limit int
+
+	// Window is the period Limit applies to. Defaults to one minute.
+	Window time.Duration
+
+	// Burst is the token bucket capacity. Defaults to Limit.
+	Burst int
+
+	// KeyFunc identifies the client. Defaults to KeyByIP().
+	KeyFunc func(*Context) string
+
+	// Store keeps the limiter state. Defaults to a new in-memory store.
+	Store LimiterStore
+
+	// Skip excludes requests from rate limiting when it returns true.
+	Skip func(*Context) bool
+}
+
+// KeyByIP identifies clients by their IP address, as resolved by
+// Context.ClientIP.
+func KeyByIP() func(*Context) string {
+	return func(c *Context) string {
+		return c.ClientIP()
+	}
+}
+
+// KeyByHeader identifies clients by the value of a request header,
+// such as an API key.
+//
+// Requests without the header fall back to the client IP.
+func KeyByHeader(name string) func(*Context) string {
+	byIP := KeyByIP()
+	return func(c *Context) string {
+		if v := c.Request.Header.Get(name); v != "" {
+			return v
+		}
+		return byIP(c)
+	}
+}
+
+// KeyByContextValue identifies clients by a value stored in the
+// Context with Set, such as the authenticated user.
+//
+// Requests without the value fall back to the client IP.
+func KeyByContextValue(key string) func(*Context) string {
+	byIP := KeyByIP()
+	return func(c *Context) string {
+		if v, ok := c.Get(key); ok {
+			return fmt.Sprint(v)
+		}
+		return byIP(c)
+	}
+}
+
+// RateLimit returns a middleware that limits how often a client may
+// call the routes it is attached to.
+//
+// Every response carries RateLimit-Limit, RateLimit-Remaining,
+// RateLimit-Reset and RateLimit-Policy headers. Rejected requests
+// receive 429 Too Many Requests with a Retry-After header.
+//
+// Each call creates an independent limiter, so different limits can be
+// attached to different groups:
+//
+//	app.Use(mows.RateLimit(mows.RateLimitConfig{Limit: 100}))
+//
+//	login := app.Group("/login")
+//	login.Use(mows.RateLimit(mows.RateLimitConfig{Limit: 5}))
+func RateLimit(config RateLimitConfig) Middleware {
+	if config.Limit <= 0 {
+		panic("mows: rate limit must be positive")
+	}
+	if config.Window <= 0 {
+		config.Window = time.Minute
+	}
+	if config.Burst <= 0 {
+		config.Burst = config.Limit
+	}
+	if config.KeyFunc == nil {
+		config.KeyFunc = KeyByIP()
+	}
+	if config.Store == nil {
+		config.Store = NewMemoryLimiterStore()
+	}
+
+	rule := RateLimitRule{
+		Algorithm: config.Algorithm,
+		Limit:     config.Limit,
+		Window:    config.Window,
+		Burst:     config.Burst,
+	}
+	window := int(config.Window.Seconds())
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			if config.Skip != nil && config.Skip(c) {
+				return next(c)
+			}
+
+			res, err := config.Store.Take(config.KeyFunc(c), rule, time.Now())
+			if err != nil {
+				return err
+			}
+
+			h := c.Writer.Header()
+			h.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+			h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+			// advertise the quota the store enforces, which is Burst for
+			// the token bucket, so both headers agree
+			h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", res.Limit, window))
+
+			if !res.Allowed {
+				h.Set("Retry-After", strconv.Itoa(ceilSeconds(res.RetryAfter)))
+				return NewHTTPError(http.StatusTooManyRequests, "")
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// ceilSeconds rounds a duration up to whole seconds.
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+const limiterShards = 32
+
+// memoryLimiterStore is an in-memory LimiterStore with sharded locks.
+type memoryLimiterStore struct {
+	shards [limiterShards]limiterShard
+}
+
+type limiterShard struct {
+	mu        sync.Mutex
+	entries   map[string]*limiterEntry
+	lastSweep time.Time
+}
+
+// limiterEntry holds the state for both algorithms.
+type limiterEntry struct {
+	// token bucket
+	tokens float64
+	last   time.Time
+
+	// sliding window
+	windowStart time.Time
+	prevCount   int
+	currCount   int
+
+	lastSeen time.Time
+	ttl      time.Duration
+}
+
+// NewMemoryLimiterStore creates an in-memory LimiterStore.
+//
+// Keys are spread over sharded locks to reduce contention, and idle
+// keys are evicted lazily once their state has fully reset.
+func NewMemoryLimiterStore() LimiterStore {
+	s := &memoryLimiterStore{}
+	for i := range s.shards {
+		s.shards[i].entries = make(map[string]*limiterEntry)
+	}
+	return s
+}
+
+// Take implements LimiterStore.
+func (s *memoryLimiterStore) Take(key string, rule RateLimitRule, now time.Time) (RateLimitResult, error) {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	shard := &s.shards[h.Sum32()%limiterShards]
+
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	shard.sweep(now, rule.Window)
+
+	e, ok := shard.entries[key]
+	if !ok {
+		e = &limiterEntry{
+			tokens:      float64(rule.Burst),
+			last:        now,
+			windowStart: now,
+		}
+		shard.entries[key] = e
+	}
+	e.lastSeen = now
+	e.ttl = 2 * rule.Window
+
+	if rule.Algorithm == SlidingWindow {
+		return e.takeSlidingWindow(rule, now), nil
+	}
+	return e.takeTokenBucket(rule, now), nil
+}
+
+// sweep removes idle entries, at most once per window.
+func (s *limiterShard) sweep(now time.Time, window time.Duration) {
+	if now.Sub(s.lastSweep) < window {
+		return
+	}
+	s.lastSweep = now
+
+	for key, e := range s.entries {
+		if now.Sub(e.lastSeen) > e.ttl {
+			delete(s.entries, key)
+		}
+	}
+}
+
+func (e *limiterEntry) takeTokenBucket(rule RateLimitRule, now time.Time) RateLimitResult {
+	rate := float64(rule.Limit) / rule.Window.Seconds()
+
+	e.tokens = math.Min(float64(rule.Burst), e.tokens+now.Sub(e.last).Seconds()*rate)
+	e.last = now
+
+	res := RateLimitResult{Limit: rule.Burst}
+	if e.tokens >= 1 {
+		e.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = secondsToDuration((1 - e.tokens) / rate)
+	}
+
+	res.Remaining = int(e.tokens)
+	res.Reset = secondsToDuration((float64(rule.Burst) - e.tokens) / rate)
+	return res
+}
+
+func (e *limiterEntry) takeSlidingWindow(rule RateLimitRule, now time.Time) RateLimitResult {
+	elapsed := now.Sub(e.windowStart)
+	if elapsed >= rule.Window {
+		windows := elapsed / rule.Window
+		if windows == 1 {
+			e.prevCount = e.currCount
+		} else {
+			e.prevCount = 0
+		}
+		e.currCount = 0
+		e.windowStart = e.windowStart.Add(windows * rule.Window)
+		elapsed = now.Sub(e.windowStart)
+	}
+
+	weight := 1 - float64(elapsed)/float64(rule.Window)
+	estimate := float64(e.prevCount)*weight + float64(e.currCount)
+
+	res := RateLimitResult{
+		Limit: rule.Limit,
+		Reset: rule.Window - elapsed,
+	}
+
+	if estimate < float64(rule.Limit) {
+		e.currCount++
+		estimate++
+		res.Allowed = true
+	} else {
+		res.RetryAfter = res.Reset
+		// the previous window decays over time; find when it frees a slot
+		if e.prevCount > 0 && e.currCount < rule.Limit {
+			free := 1 - float64(rule.Limit-e.currCount)/float64(e.prevCount)
+			wait := time.Duration(free*float64(rule.Window)) - elapsed
+			if wait > 0 && wait < res.RetryAfter {
+				res.RetryAfter = wait
+			}
+		}
+	}
+
+	res.Remaining = max(0, rule.Limit-int(math.Ceil(estimate)))
+	return res
+}
+
+// secondsToDuration converts fractional seconds to a Duration.
+func secondsToDuration(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
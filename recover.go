@@ -1,25 +1,247 @@
 package mows
 
-import "net/http"
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"syscall"
+)
+
+// PanicError is the error produced when a handler panics.
+//
+// Recover passes it to the engine's ErrorHandler, so custom handlers
+// can detect panics with errors.As.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value any
+
+	// Stack is the goroutine stack trace at the time of the panic.
+	Stack []byte
+
+	// File and Line locate the panic in the source, when known.
+	File string
+	Line int
+}
+
+// Error implements the error interface.
+func (p *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", p.Value)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (p *PanicError) Unwrap() error {
+	err, _ := p.Value.(error)
+	return err
+}
+
+// newPanicError captures the stack of a recovered panic.
+//
+// It must be called from the deferred function that recovered.
+func newPanicError(value any) *PanicError {
+	if pe, ok := value.(*PanicError); ok {
+		return pe
+	}
+
+	pe := &PanicError{Value: value, Stack: debug.Stack()}
+	pe.File, pe.Line = panicOrigin()
+	return pe
+}
+
+// panicOrigin returns the location of the first frame after
+// runtime.gopanic that is not part of the runtime.
+func panicOrigin() (string, int) {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	afterPanic := false
+	for {
+		frame, more := frames.Next()
+		if afterPanic && !strings.HasPrefix(frame.Function, "runtime.") {
+			return frame.File, frame.Line
+		}
+		if frame.Function == "runtime.gopanic" {
+			afterPanic = true
+		}
+		if !more {
+			return "", 0
+		}
+	}
+}
+
+// RecoverConfig defines the configuration for the Recover middleware.
+type RecoverConfig struct {
+	// OnPanic is called for every recovered panic, e.g. to report it to
+	// an error tracking service. It runs before the error handler.
+	OnPanic func(c *Context, err *PanicError)
+}
 
 // Recover returns a middleware that recovers from panics.
 //
+// See RecoverWithConfig for details.
+func Recover() Middleware {
+	return RecoverWithConfig(RecoverConfig{})
+}
+
+// RecoverWithConfig returns a Recover middleware with custom settings.
+//
 // If a panic occurs, the middleware:
 //
 //   - Prevents the server from crashing
-//   - Returns HTTP 500
-//   - Sends the panic message as JSON
-func Recover() Middleware {
+//   - Logs the panic and stack trace with the request logger
+//   - Returns a PanicError, which the error handler turns into a 500
+//     (with a stack trace page in DevMode)
+//
+// Panics with http.ErrAbortHandler are re-raised so net/http aborts the
+// response as intended. Panics caused by the client closing the
+// connection (broken pipe, connection reset) are logged but no response
+// is written.
+func RecoverWithConfig(config RecoverConfig) Middleware {
 	return func(next HandlerFunc) HandlerFunc {
-		return func(c *Context) error {
+		return func(c *Context) (err error) {
 			defer func() {
-				if err := recover(); err != nil {
-					c.JSON(http.StatusInternalServerError, map[string]string{
-						"error": "internal server error",
-					})
+				value := recover()
+				if value == nil {
+					return
+				}
+				if value == http.ErrAbortHandler {
+					panic(value)
 				}
+
+				pe := newPanicError(value)
+
+				if brokenConnection(pe) {
+					c.Logger().Warn("client connection closed", "error", pe.Value)
+					err = nil
+					return
+				}
+
+				c.Logger().Error("panic recovered",
+					"error", pe.Value,
+					"file", pe.File,
+					"line", pe.Line,
+					"stack", string(pe.Stack),
+				)
+
+				if config.OnPanic != nil {
+					config.OnPanic(c, pe)
+				}
+
+				err = pe
 			}()
 			return next(c)
 		}
 	}
 }
+
+// brokenConnection reports whether the panic was caused by writing to a
+// connection the client has already closed.
+func brokenConnection(pe *PanicError) bool {
+	err := pe.Unwrap()
+	return err != nil && (errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET))
+}
+
+// writePanic responds to a recovered panic with status 500.
+//
+// Outside DevMode the response never includes panic details. In DevMode
+// it shows the panic value, the stack trace and the source around the
+// panic, as HTML for browsers and JSON otherwise.
+func writePanic(c *Context, pe *PanicError) {
+	if !c.engine.devMode {
+		c.JSON(http.StatusInternalServerError, serverError{
+			Error:     "internal server error",
+			RequestID: c.RequestID(),
+		})
+		return
+	}
+
+	page := panicPage{
+		Error:     pe.Error(),
+		File:      pe.File,
+		Line:      pe.Line,
+		Source:    sourceSnippet(pe.File, pe.Line, 5),
+		Stack:     string(pe.Stack),
+		RequestID: c.RequestID(),
+	}
+
+	if strings.Contains(c.Request.Header.Get("Accept"), "text/html") {
+		c.Writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+		c.Writer.WriteHeader(http.StatusInternalServerError)
+		panicTemplate.Execute(c.Writer, page)
+		return
+	}
+
+	c.JSON(http.StatusInternalServerError, page)
+}
+
+// panicPage is the DevMode description of a panic.
+type panicPage struct {
+	Error     string       `json:"error"`
+	File      string       `json:"file,omitempty"`
+	Line      int          `json:"line,omitempty"`
+	Source    []sourceLine `json:"source,omitempty"`
+	Stack     string       `json:"stack"`
+	RequestID string       `json:"request_id,omitempty"`
+}
+
+// sourceLine is a single line of a source snippet.
+type sourceLine struct {
+	Number  int    `json:"number"`
+	Code    string `json:"code"`
+	Current bool   `json:"current,omitempty"`
+}
+
+// sourceSnippet returns up to context lines around line in file.
+func sourceSnippet(file string, line, context int) []sourceLine {
+	if file == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+
+	lines := strings.Split(string(data), "\n")
+	start := max(line-context, 1)
+	end := min(line+context, len(lines))
+
+	var snippet []sourceLine
+	for n := start; n <= end; n++ {
+		snippet = append(snippet, sourceLine{
+			Number:  n,
+			Code:    lines[n-1],
+			Current: n == line,
+		})
+	}
+	return snippet
+}
+
+var panicTemplate = template.Must(template.New("panic").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{ .Error }}</title>
+<style>
+body { font-family: sans-serif; margin: 2rem; color: #222; }
+h1 { color: #b00020; font-size: 1.4rem; }
+pre { background: #f5f5f5; padding: 1rem; overflow-x: auto; }
+.current { background: #ffe0e0; }
+</style>
+</head>
+<body>
+<h1>{{ .Error }}</h1>
+{{ if .File }}<p>{{ .File }}:{{ .Line }}</p>{{ end }}
+{{ if .Source }}<pre>{{ range .Source }}<span{{ if .Current }} class="current"{{ end }}>{{ printf "%4d" .Number }}  {{ .Code }}</span>
+{{ end }}</pre>{{ end }}
+<h2>Stack trace</h2>
+<pre>{{ .Stack }}</pre>
+{{ if .RequestID }}<p>Request ID: {{ .RequestID }}</p>{{ end }}
+</body>
+</html>
+`))
@@ -0,0 +1,137 @@
+package mows
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"time"
+)
+
+// RequestIDKey is the Context store key holding the request ID.
+const RequestIDKey = "request_id"
+
+// requestIDContextKey is the request context key holding the request ID.
+type requestIDContextKey struct{}
+
+// RequestIDConfig defines the configuration for the RequestID middleware.
+type RequestIDConfig struct {
+	// Header is read from the request and set on the response.
+	// Defaults to "X-Request-ID".
+	Header string
+
+	// Generator creates IDs for requests without a valid incoming ID.
+	// Defaults to NewUUIDv7.
+	Generator func() string
+
+	// MaxLength is the longest incoming ID that is accepted.
+	// Defaults to 128.
+	MaxLength int
+}
+
+// RequestID returns a middleware that assigns every request an ID
+// using the X-Request-ID header.
+//
+// See RequestIDWithConfig for details.
+func RequestID() Middleware {
+	return RequestIDWithConfig(RequestIDConfig{})
+}
+
+// RequestIDWithConfig returns a RequestID middleware with custom settings.
+//
+// An incoming ID is reused if it is at most MaxLength characters of
+// letters, digits, '-', '_', '.' or ':'; otherwise a new one is
+// generated. The ID is:
+//
+//   - Stored in the Context (c.RequestID()) and the request context
+//     (RequestIDFromContext), so outgoing calls can propagate it
+//   - Echoed in the response header
+//   - Included by Logger, AccessLog, Context.Logger and the default
+//     error handler
+func RequestIDWithConfig(config RequestIDConfig) Middleware {
+	if config.Header == "" {
+		config.Header = "X-Request-ID"
+	}
+	if config.Generator == nil {
+		config.Generator = NewUUIDv7
+	}
+	if config.MaxLength <= 0 {
+		config.MaxLength = 128
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			id := c.Request.Header.Get(config.Header)
+			if !validRequestID(id, config.MaxLength) {
+				id = config.Generator()
+			}
+
+			c.Set(RequestIDKey, id)
+			c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), requestIDContextKey{}, id))
+			c.Writer.Header().Set(config.Header, id)
+
+			// rebuild the request logger so it picks up the ID
+			c.logger = nil
+
+			return next(c)
+		}
+	}
+}
+
+// RequestID returns the ID assigned by the RequestID middleware, or an
+// empty string if the middleware is not in use.
+func (c *Context) RequestID() string {
+	id, _ := c.keys[RequestIDKey].(string)
+	return id
+}
+
+// RequestIDFromContext returns the request ID stored in ctx by the
+// RequestID middleware.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey{}).(string)
+	return id
+}
+
+// validRequestID reports whether an incoming ID is safe to reuse.
+func validRequestID(id string, maxLength int) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		ch := id[i]
+		switch {
+		case ch >= 'a' && ch <= 'z', ch >= 'A' && ch <= 'Z', ch >= '0' && ch <= '9':
+		case ch == '-', ch == '_', ch == '.', ch == ':':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// NewUUIDv7 returns a random, time-ordered UUID (RFC 9562 version 7).
+func NewUUIDv7() string {
+	var u [16]byte
+	rand.Read(u[6:])
+
+	ms := uint64(time.Now().UnixMilli())
+	binary.BigEndian.PutUint64(u[0:8], ms<<16|uint64(binary.BigEndian.Uint16(u[6:8])))
+
+	u[6] = (u[6] & 0x0f) | 0x70 // version 7
+	u[8] = (u[8] & 0x3f) | 0x80 // RFC 9562 variant
+
+	var buf [36]byte
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+
+	return string(buf[:])
+}
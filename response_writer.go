@@ -30,3 +30,19 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 	rw.size += size
 	return size, err
 }
+
+// Flush sends any buffered data to the client.
+//
+// It is a no-op if the underlying ResponseWriter does not support flushing.
+func (rw *responseWriter) Flush() {
+	if f, ok := rw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying ResponseWriter.
+//
+// It allows http.ResponseController to reach the original writer.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
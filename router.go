@@ -1,11 +1,158 @@
 package mows
 
-import "strings"
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
 
 // route represents a static route.
 type route struct {
+	pattern     string
 	handler     HandlerFunc
 	middlewares []Middleware
+	meta        *RouteMeta
+
+	// group, handlerName, middlewareNames and policies describe the
+	// route for Engine.Routes and Engine.RouteGuards.
+	group           string
+	handlerName     string
+	middlewareNames []string
+	policies        []Policy
+}
+
+// RouteMeta holds data attached to a route at registration.
+type RouteMeta struct {
+	// Requirements lists what the principal must satisfy to access the
+	// route, checked by the Authorize middleware. See RouterGroup.Require.
+	Requirements []string
+
+	// Summary, Description, OperationID, Tags and Deprecated document the
+	// route in the OpenAPI document.
+	Summary     string
+	Description string
+	OperationID string
+	Tags        []string
+	Deprecated  bool
+
+	// Hidden excludes the route from the OpenAPI document.
+	Hidden bool
+
+	// Request is the struct describing the route's parameters and body.
+	// See Route.Request.
+	Request reflect.Type
+
+	// Responses lists the documented responses.
+	Responses []RouteResponse
+
+	// Params documents parameters not described by Request.
+	Params []RouteParam
+}
+
+// RouteResponse documents a response of a route.
+type RouteResponse struct {
+	Status int
+
+	// Type is the type of the JSON body, or nil for an empty body.
+	Type reflect.Type
+}
+
+// RouteParam documents a route parameter.
+type RouteParam struct {
+	// In is "path", "query" or "header".
+	In          string
+	Name        string
+	Description string
+}
+
+// Route is returned when registering a route. Its methods attach
+// documentation to the route, used by the OpenAPI document.
+//
+// Example:
+//
+//	app.POST("/users", createUser).
+//	    Summary("Create a user").
+//	    Tags("users").
+//	    Request(CreateUserRequest{}).
+//	    Response(http.StatusCreated, User{})
+type Route struct {
+	meta *RouteMeta
+}
+
+// Summary sets a short summary of what the route does.
+func (r *Route) Summary(summary string) *Route {
+	r.meta.Summary = summary
+	return r
+}
+
+// Description sets a longer explanation of the route. CommonMark is allowed.
+func (r *Route) Description(description string) *Route {
+	r.meta.Description = description
+	return r
+}
+
+// OperationID sets the unique ID of the operation, used by client generators.
+func (r *Route) OperationID(id string) *Route {
+	r.meta.OperationID = id
+	return r
+}
+
+// Tags adds tags grouping the route in documentation.
+func (r *Route) Tags(tags ...string) *Route {
+	r.meta.Tags = append(r.meta.Tags, tags...)
+	return r
+}
+
+// Deprecated marks the route as deprecated.
+func (r *Route) Deprecated() *Route {
+	r.meta.Deprecated = true
+	return r
+}
+
+// Hidden excludes the route from the OpenAPI document.
+func (r *Route) Hidden() *Route {
+	r.meta.Hidden = true
+	return r
+}
+
+// Request documents the route's input with a struct value.
+//
+// Fields tagged uri, query or header are path, query and header
+// parameters; the other fields form the JSON body. Constraints come
+// from validate tags.
+//
+// Example:
+//
+//	type UpdateUserRequest struct {
+//	    ID    string `uri:"id"`
+//	    Name  string `json:"name" validate:"required,min=3"`
+//	    Email string `json:"email" validate:"required,email"`
+//	}
+func (r *Route) Request(v any) *Route {
+	r.meta.Request = reflect.TypeOf(v)
+	return r
+}
+
+// Response documents a response with the value of its JSON body, or
+// nil for an empty body. It replaces an earlier response with the same
+// status, such as the one documented by Typed.
+func (r *Route) Response(status int, v any) *Route {
+	resp := RouteResponse{Status: status, Type: reflect.TypeOf(v)}
+	for i := range r.meta.Responses {
+		if r.meta.Responses[i].Status == status {
+			r.meta.Responses[i] = resp
+			return r
+		}
+	}
+	r.meta.Responses = append(r.meta.Responses, resp)
+	return r
+}
+
+// Param documents a parameter, or adds a description to one described
+// by Request. in is "path", "query" or "header".
+func (r *Route) Param(in, name, description string) *Route {
+	r.meta.Params = append(r.meta.Params, RouteParam{In: in, Name: name, Description: description})
+	return r
 }
 
 // paramRoute represents a route containing path parameters.
@@ -33,23 +180,23 @@ func NewRouter() *Router {
 }
 
 // GET registers a route that responds to HTTP GET requests.
-func (e *Engine) GET(path string, handlers ...HandlerFunc) {
-	e.rootGroup.GET(path, handlers...)
+func (e *Engine) GET(path string, handlers ...HandlerFunc) *Route {
+	return e.rootGroup.GET(path, handlers...)
 }
 
 // POST registers a route that responds to HTTP POST requests.
-func (e *Engine) POST(path string, handlers ...HandlerFunc) {
-	e.rootGroup.POST(path, handlers...)
+func (e *Engine) POST(path string, handlers ...HandlerFunc) *Route {
+	return e.rootGroup.POST(path, handlers...)
 }
 
 // PUT registers a route that responds to HTTP PUT requests.
-func (e *Engine) PUT(path string, handlers ...HandlerFunc) {
-	e.rootGroup.PUT(path, handlers...)
+func (e *Engine) PUT(path string, handlers ...HandlerFunc) *Route {
+	return e.rootGroup.PUT(path, handlers...)
 }
 
 // DELETE registers a route that responds to HTTP DELETE requests.
-func (e *Engine) DELETE(path string, handlers ...HandlerFunc) {
-	e.rootGroup.DELETE(path, handlers...)
+func (e *Engine) DELETE(path string, handlers ...HandlerFunc) *Route {
+	return e.rootGroup.DELETE(path, handlers...)
 }
 
 // find matches an incoming request path and returns the handler and params.
@@ -115,12 +262,9 @@ func hasParams(path string) bool {
 	return strings.Contains(path, ":")
 }
 
-// addWithMiddleware registers a route and applies middleware chain.
-func (r *Router) addWithMiddleware(method string, path string, handler HandlerFunc, middlewares []Middleware) {
-	rt := route{
-		handler:     handler,
-		middlewares: middlewares,
-	}
+// add registers a route with its middleware chain.
+func (r *Router) add(method string, rt route) {
+	path := rt.pattern
 
 	// static route
 	if !hasParams(path) {
@@ -138,3 +282,34 @@ func (r *Router) addWithMiddleware(method string, path string, handler HandlerFu
 		route:     rt,
 	})
 }
+
+// each calls fn for every registered route, ordered by pattern, then method.
+func (r *Router) each(fn func(method string, rt *route)) {
+	type entry struct {
+		method string
+		rt     *route
+	}
+
+	var entries []entry
+	for method, routes := range r.staticRoutes {
+		for _, rt := range routes {
+			entries = append(entries, entry{method, &rt})
+		}
+	}
+	for method, routes := range r.paramRoutes {
+		for i := range routes {
+			entries = append(entries, entry{method, &routes[i].route})
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].rt.pattern != entries[j].rt.pattern {
+			return entries[i].rt.pattern < entries[j].rt.pattern
+		}
+		return entries[i].method < entries[j].method
+	})
+
+	for _, e := range entries {
+		fn(e.method, e.rt)
+	}
+}
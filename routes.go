@@ -0,0 +1,134 @@
+package mows
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// RouteInfo describes a registered route.
+type RouteInfo struct {
+	Method  string
+	Pattern string
+
+	// Handler is the name of the final handler, e.g. "main.getUser".
+	// Typed handlers report the adapted function. Closures are named
+	// after their enclosing function.
+	Handler string
+
+	// Middlewares names the middleware that run for the route in order,
+	// global ones first, e.g. "mows.Logger" and "mows.JWT". Extra
+	// handlers passed at registration are listed last.
+	Middlewares []string
+
+	// Group is the prefix of the group the route was registered on,
+	// empty for routes registered on the engine.
+	Group string
+
+	// Meta is the route's metadata (see Route).
+	Meta RouteMeta
+}
+
+// Routes returns the registered routes sorted by pattern, then method.
+//
+// It can be used to check how routes are protected in tests:
+//
+//	for _, r := range app.Routes() {
+//	    if strings.HasPrefix(r.Pattern, "/admin") && !slices.Contains(r.Middlewares, "mows.JWT") {
+//	        t.Errorf("%s %s is not authenticated", r.Method, r.Pattern)
+//	    }
+//	}
+func (e *Engine) Routes() []RouteInfo {
+	entries := e.routes()
+	routes := make([]RouteInfo, len(entries))
+	for i, entry := range entries {
+		routes[i] = entry.RouteInfo
+	}
+	return routes
+}
+
+// routeEntry is a RouteInfo with the policies of the Authorize
+// middleware that run for the route.
+type routeEntry struct {
+	RouteInfo
+	policies []Policy
+}
+
+// routes describes the registered routes for Routes and RouteGuards.
+func (e *Engine) routes() []routeEntry {
+	var names []string
+	var policies []Policy
+	for _, m := range e.middlewares {
+		names = append(names, middlewareName(m))
+		if policy := authorizePolicy(m); policy != nil {
+			policies = append(policies, policy)
+		}
+	}
+
+	var routes []routeEntry
+	e.router.each(func(method string, rt *route) {
+		routes = append(routes, routeEntry{
+			RouteInfo: RouteInfo{
+				Method:      method,
+				Pattern:     rt.pattern,
+				Handler:     rt.handlerName,
+				Middlewares: slices.Concat(names, rt.middlewareNames),
+				Group:       rt.group,
+				Meta:        *rt.meta,
+			},
+			policies: slices.Concat(policies, rt.policies),
+		})
+	})
+	return routes
+}
+
+// WriteRoutes writes the registered routes to w as a table.
+//
+// Example output:
+//
+//	METHOD  PATTERN     HANDLER       MIDDLEWARES
+//	GET     /admin/:id  main.getUser  mows.Logger, mows.JWT
+func (e *Engine) WriteRoutes(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "METHOD\tPATTERN\tHANDLER\tMIDDLEWARES")
+	for _, r := range e.Routes() {
+		middlewares := strings.Join(r.Middlewares, ", ")
+		if middlewares == "" {
+			middlewares = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.Method, r.Pattern, r.Handler, middlewares)
+	}
+	return tw.Flush()
+}
+
+// RoutesHandler returns a handler that responds with the route table
+// written by WriteRoutes, as text/plain.
+//
+// The table reveals the application's internals; guard the endpoint or
+// only register it in development.
+//
+// Example:
+//
+//	admin.GET("/routes", app.RoutesHandler())
+func (e *Engine) RoutesHandler() HandlerFunc {
+	return func(c *Context) error {
+		var buf bytes.Buffer
+		if err := e.WriteRoutes(&buf); err != nil {
+			return err
+		}
+		c.Writer.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		return c.Text(http.StatusOK, buf.String())
+	}
+}
+
+// printRoutes writes the route table to stderr in DevMode.
+func (e *Engine) printRoutes() {
+	if e.devMode {
+		e.WriteRoutes(os.Stderr)
+	}
+}
@@ -0,0 +1,443 @@
+package mows
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// CSPNonceKey is the Context store key holding the CSP nonce of the request.
+const CSPNonceKey = "csp_nonce"
+
+// CSPNonce is a source placeholder replaced by the per-request nonce,
+// e.g. 'nonce-q8E3...', when the policy is sent.
+const CSPNonce = "'nonce'"
+
+// SecureConfig defines the configuration for the Secure middleware.
+//
+// Empty fields are not sent. Start from DefaultSecureConfig for
+// recommended values.
+type SecureConfig struct {
+	// HSTSMaxAge enables Strict-Transport-Security on HTTPS requests.
+	HSTSMaxAge time.Duration
+
+	// HSTSIncludeSubdomains applies HSTS to all subdomains.
+	HSTSIncludeSubdomains bool
+
+	// HSTSPreload asks browsers to preload HSTS for the domain.
+	HSTSPreload bool
+
+	// ContentTypeNosniff sends X-Content-Type-Options: nosniff.
+	ContentTypeNosniff bool
+
+	// FrameOptions is the X-Frame-Options value, "DENY" or "SAMEORIGIN".
+	// Prefer the frame-ancestors directive of CSP in new code.
+	FrameOptions string
+
+	// ReferrerPolicy is the Referrer-Policy value.
+	ReferrerPolicy string
+
+	// PermissionsPolicy is the Permissions-Policy value,
+	// e.g. "camera=(), microphone=()".
+	PermissionsPolicy string
+
+	// CrossOriginOpenerPolicy is the Cross-Origin-Opener-Policy value.
+	CrossOriginOpenerPolicy string
+
+	// CrossOriginEmbedderPolicy is the Cross-Origin-Embedder-Policy value.
+	CrossOriginEmbedderPolicy string
+
+	// CrossOriginResourcePolicy is the Cross-Origin-Resource-Policy value.
+	CrossOriginResourcePolicy string
+
+	// CSP is the Content-Security-Policy. Sources may include CSPNonce.
+	CSP *CSP
+
+	// CSPReportOnly sends the policy as Content-Security-Policy-Report-Only,
+	// so violations are reported but not blocked.
+	CSPReportOnly bool
+
+	// CSPReportURI, when set, is where browsers send violation reports.
+	// Serve it with CSPReportHandler.
+	CSPReportURI string
+}
+
+// DefaultSecureConfig returns the recommended security headers,
+// including a strict nonce-based Content-Security-Policy.
+func DefaultSecureConfig() SecureConfig {
+	return SecureConfig{
+		HSTSMaxAge:                365 * 24 * time.Hour,
+		HSTSIncludeSubdomains:     true,
+		ContentTypeNosniff:        true,
+		FrameOptions:              "DENY",
+		ReferrerPolicy:            "strict-origin-when-cross-origin",
+		PermissionsPolicy:         "camera=(), microphone=(), geolocation=(), payment=()",
+		CrossOriginOpenerPolicy:   "same-origin",
+		CrossOriginResourcePolicy: "same-origin",
+		CSP: NewCSP().
+			DefaultSrc("'self'").
+			ScriptSrc("'self'", CSPNonce).
+			StyleSrc("'self'", CSPNonce).
+			ObjectSrc("'none'").
+			BaseURI("'self'").
+			FrameAncestors("'none'"),
+	}
+}
+
+// Secure returns a middleware that sets security response headers.
+//
+// HSTS is only sent on HTTPS requests (see Context.Scheme). When the
+// policy uses CSPNonce, every request gets a fresh nonce, available
+// from c.CSPNonce() and in templates as {{ cspNonce }}.
+//
+// Example:
+//
+//	config := mows.DefaultSecureConfig()
+//	config.CSPReportOnly = true
+//	config.CSPReportURI = "/csp-report"
+//
+//	app.Use(mows.Secure(config))
+//	app.POST("/csp-report", mows.CSPReportHandler(nil))
+//
+// and in a template:
+//
+//	<script nonce="{{ cspNonce }}">...</script>
+func Secure(config SecureConfig) Middleware {
+	var hsts string
+	if config.HSTSMaxAge > 0 {
+		hsts = fmt.Sprintf("max-age=%d", int(config.HSTSMaxAge.Seconds()))
+		if config.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+		if config.HSTSPreload {
+			hsts += "; preload"
+		}
+	}
+
+	static := map[string]string{
+		"X-Frame-Options":              config.FrameOptions,
+		"Referrer-Policy":              config.ReferrerPolicy,
+		"Permissions-Policy":           config.PermissionsPolicy,
+		"Cross-Origin-Opener-Policy":   config.CrossOriginOpenerPolicy,
+		"Cross-Origin-Embedder-Policy": config.CrossOriginEmbedderPolicy,
+		"Cross-Origin-Resource-Policy": config.CrossOriginResourcePolicy,
+	}
+	if config.ContentTypeNosniff {
+		static["X-Content-Type-Options"] = "nosniff"
+	}
+
+	var csp *CSP
+	if config.CSP != nil {
+		csp = config.CSP.clone()
+		if config.CSPReportURI != "" {
+			csp.Add("report-uri", config.CSPReportURI)
+			csp.Add("report-to", "csp-endpoint")
+			static["Reporting-Endpoints"] = fmt.Sprintf("csp-endpoint=%q", config.CSPReportURI)
+		}
+	}
+	cspHeader := "Content-Security-Policy"
+	if config.CSPReportOnly {
+		cspHeader = "Content-Security-Policy-Report-Only"
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			h := c.Writer.Header()
+			for name, value := range static {
+				if value != "" {
+					h.Set(name, value)
+				}
+			}
+			if hsts != "" && c.Scheme() == "https" {
+				h.Set("Strict-Transport-Security", hsts)
+			}
+
+			if csp != nil {
+				var nonce string
+				if csp.usesNonce() {
+					nonce = newNonce()
+					c.Set(CSPNonceKey, nonce)
+					c.setTemplateFunc("cspNonce", func() string {
+						return nonce
+					})
+				}
+				h.Set(cspHeader, csp.build(nonce))
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// CSPNonce returns the Content-Security-Policy nonce of the request, or
+// an empty string if the Secure middleware did not generate one.
+func (c *Context) CSPNonce() string {
+	nonce, _ := c.keys[CSPNonceKey].(string)
+	return nonce
+}
+
+// newNonce returns 16 random bytes, base64url encoded so templates
+// never need to escape it.
+func newNonce() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// CSP builds a Content-Security-Policy.
+//
+// Example:
+//
+//	csp := mows.NewCSP().
+//	    DefaultSrc("'self'").
+//	    ScriptSrc("'self'", mows.CSPNonce, "https://cdn.example.com").
+//	    ImgSrc("'self'", "data:")
+type CSP struct {
+	directives []cspDirective
+}
+
+type cspDirective struct {
+	name    string
+	sources []string
+}
+
+// NewCSP creates an empty policy.
+func NewCSP() *CSP {
+	return &CSP{}
+}
+
+// Add appends sources to a directive, creating it if needed.
+func (p *CSP) Add(directive string, sources ...string) *CSP {
+	for i := range p.directives {
+		if p.directives[i].name == directive {
+			p.directives[i].sources = append(p.directives[i].sources, sources...)
+			return p
+		}
+	}
+	p.directives = append(p.directives, cspDirective{name: directive, sources: sources})
+	return p
+}
+
+// DefaultSrc adds sources to default-src.
+func (p *CSP) DefaultSrc(sources ...string) *CSP { return p.Add("default-src", sources...) }
+
+// ScriptSrc adds sources to script-src.
+func (p *CSP) ScriptSrc(sources ...string) *CSP { return p.Add("script-src", sources...) }
+
+// StyleSrc adds sources to style-src.
+func (p *CSP) StyleSrc(sources ...string) *CSP { return p.Add("style-src", sources...) }
+
+// ImgSrc adds sources to img-src.
+func (p *CSP) ImgSrc(sources ...string) *CSP { return p.Add("img-src", sources...) }
+
+// ConnectSrc adds sources to connect-src.
+func (p *CSP) ConnectSrc(sources ...string) *CSP { return p.Add("connect-src", sources...) }
+
+// FontSrc adds sources to font-src.
+func (p *CSP) FontSrc(sources ...string) *CSP { return p.Add("font-src", sources...) }
+
+// ObjectSrc adds sources to object-src.
+func (p *CSP) ObjectSrc(sources ...string) *CSP { return p.Add("object-src", sources...) }
+
+// FrameSrc adds sources to frame-src.
+func (p *CSP) FrameSrc(sources ...string) *CSP { return p.Add("frame-src", sources...) }
+
+// FrameAncestors adds sources to frame-ancestors, which controls who
+// may embed the page and supersedes X-Frame-Options.
+func (p *CSP) FrameAncestors(sources ...string) *CSP { return p.Add("frame-ancestors", sources...) }
+
+// BaseURI adds sources to base-uri.
+func (p *CSP) BaseURI(sources ...string) *CSP { return p.Add("base-uri", sources...) }
+
+// FormAction adds sources to form-action.
+func (p *CSP) FormAction(sources ...string) *CSP { return p.Add("form-action", sources...) }
+
+// String returns the policy with CSPNonce placeholders left in place.
+func (p *CSP) String() string {
+	return p.build("")
+}
+
+// build renders the policy, replacing CSPNonce with nonce.
+func (p *CSP) build(nonce string) string {
+	var b strings.Builder
+	for i, d := range p.directives {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString(d.name)
+		for _, source := range d.sources {
+			if source == CSPNonce && nonce != "" {
+				source = "'nonce-" + nonce + "'"
+			}
+			b.WriteByte(' ')
+			b.WriteString(source)
+		}
+	}
+	return b.String()
+}
+
+// usesNonce reports whether any directive contains CSPNonce.
+func (p *CSP) usesNonce() bool {
+	for _, d := range p.directives {
+		for _, source := range d.sources {
+			if source == CSPNonce {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (p *CSP) clone() *CSP {
+	clone := &CSP{}
+	for _, d := range p.directives {
+		clone.Add(d.name, d.sources...)
+	}
+	return clone
+}
+
+// CSPReport is a Content-Security-Policy violation report.
+type CSPReport struct {
+	DocumentURI        string `json:"document_uri"`
+	Referrer           string `json:"referrer,omitempty"`
+	BlockedURI         string `json:"blocked_uri"`
+	EffectiveDirective string `json:"effective_directive"`
+	OriginalPolicy     string `json:"original_policy,omitempty"`
+	Disposition        string `json:"disposition,omitempty"`
+	SourceFile         string `json:"source_file,omitempty"`
+	LineNumber         int    `json:"line_number,omitempty"`
+	ColumnNumber       int    `json:"column_number,omitempty"`
+	StatusCode         int    `json:"status_code,omitempty"`
+	Sample             string `json:"sample,omitempty"`
+}
+
+// legacyCSPReport is the "application/csp-report" body of report-uri.
+type legacyCSPReport struct {
+	Body struct {
+		DocumentURI        string `json:"document-uri"`
+		Referrer           string `json:"referrer"`
+		BlockedURI         string `json:"blocked-uri"`
+		EffectiveDirective string `json:"effective-directive"`
+		ViolatedDirective  string `json:"violated-directive"`
+		OriginalPolicy     string `json:"original-policy"`
+		Disposition        string `json:"disposition"`
+		SourceFile         string `json:"source-file"`
+		LineNumber         int    `json:"line-number"`
+		ColumnNumber       int    `json:"column-number"`
+		StatusCode         int    `json:"status-code"`
+		Sample             string `json:"script-sample"`
+	} `json:"csp-report"`
+}
+
+// reportingAPIReport is an entry of an "application/reports+json" body
+// sent by the Reporting API (report-to).
+type reportingAPIReport struct {
+	Type string `json:"type"`
+	Body struct {
+		DocumentURL        string `json:"documentURL"`
+		Referrer           string `json:"referrer"`
+		BlockedURL         string `json:"blockedURL"`
+		EffectiveDirective string `json:"effectiveDirective"`
+		OriginalPolicy     string `json:"originalPolicy"`
+		Disposition        string `json:"disposition"`
+		SourceFile         string `json:"sourceFile"`
+		LineNumber         int    `json:"lineNumber"`
+		ColumnNumber       int    `json:"columnNumber"`
+		StatusCode         int    `json:"statusCode"`
+		Sample             string `json:"sample"`
+	} `json:"body"`
+}
+
+// CSPReportHandler returns a handler that receives CSP violation reports
+// in both the report-uri and the Reporting API formats, and passes each
+// one to fn. When fn is nil, reports are logged with the request logger.
+//
+// It responds with 204 No Content, or 400 for malformed reports.
+//
+// Example:
+//
+//	app.POST("/csp-report", mows.CSPReportHandler(func(c *mows.Context, r mows.CSPReport) {
+//	    metrics.CSPViolations.WithLabelValues(r.EffectiveDirective).Inc()
+//	}))
+func CSPReportHandler(fn func(c *Context, report CSPReport)) HandlerFunc {
+	if fn == nil {
+		fn = func(c *Context, report CSPReport) {
+			c.Logger().Warn("csp violation",
+				"document_uri", report.DocumentURI,
+				"blocked_uri", report.BlockedURI,
+				"directive", report.EffectiveDirective,
+				"disposition", report.Disposition,
+			)
+		}
+	}
+
+	return func(c *Context) error {
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, 64<<10))
+		if err != nil {
+			return err
+		}
+
+		var reports []CSPReport
+		mediaType, _, _ := mime.ParseMediaType(c.Request.Header.Get("Content-Type"))
+		if mediaType == "application/reports+json" {
+			var entries []reportingAPIReport
+			if err := json.Unmarshal(body, &entries); err != nil {
+				return NewHTTPError(http.StatusBadRequest, "invalid report")
+			}
+			for _, e := range entries {
+				if e.Type != "csp-violation" {
+					continue
+				}
+				reports = append(reports, CSPReport{
+					DocumentURI:        e.Body.DocumentURL,
+					Referrer:           e.Body.Referrer,
+					BlockedURI:         e.Body.BlockedURL,
+					EffectiveDirective: e.Body.EffectiveDirective,
+					OriginalPolicy:     e.Body.OriginalPolicy,
+					Disposition:        e.Body.Disposition,
+					SourceFile:         e.Body.SourceFile,
+					LineNumber:         e.Body.LineNumber,
+					ColumnNumber:       e.Body.ColumnNumber,
+					StatusCode:         e.Body.StatusCode,
+					Sample:             e.Body.Sample,
+				})
+			}
+		} else {
+			var legacy legacyCSPReport
+			if err := json.Unmarshal(body, &legacy); err != nil {
+				return NewHTTPError(http.StatusBadRequest, "invalid report")
+			}
+			r := legacy.Body
+			directive := r.EffectiveDirective
+			if directive == "" {
+				directive = r.ViolatedDirective
+			}
+			reports = append(reports, CSPReport{
+				DocumentURI:        r.DocumentURI,
+				Referrer:           r.Referrer,
+				BlockedURI:         r.BlockedURI,
+				EffectiveDirective: directive,
+				OriginalPolicy:     r.OriginalPolicy,
+				Disposition:        r.Disposition,
+				SourceFile:         r.SourceFile,
+				LineNumber:         r.LineNumber,
+				ColumnNumber:       r.ColumnNumber,
+				StatusCode:         r.StatusCode,
+				Sample:             r.Sample,
+			})
+		}
+
+		for _, report := range reports {
+			fn(c, report)
+		}
+
+		c.Writer.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+}
@@ -0,0 +1,247 @@
+package mows
+
+import (
+	"context"
+	"errors"
+	"log"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// ServerConfig holds the settings applied to the underlying http.Server.
+//
+// Zero values disable the corresponding limit, matching http.Server.
+// Use DefaultServerConfig as a starting point for safe defaults.
+type ServerConfig struct {
+	// ReadHeaderTimeout is the time allowed to read request headers.
+	ReadHeaderTimeout time.Duration
+
+	// ReadTimeout is the time allowed to read the entire request.
+	ReadTimeout time.Duration
+
+	// WriteTimeout is the time allowed to write the response.
+	// Prefer the Timeout middleware, which can be overridden per route.
+	WriteTimeout time.Duration
+
+	// IdleTimeout is how long keep-alive connections stay open.
+	IdleTimeout time.Duration
+
+	// MaxHeaderBytes limits the size of request headers.
+	MaxHeaderBytes int
+
+	// ErrorLog receives errors from the server, such as TLS handshake
+	// failures. Defaults to the engine logger at error level.
+	ErrorLog *log.Logger
+
+	// H2C enables HTTP/2 over cleartext connections, both with prior
+	// knowledge, as spoken by L4 load balancers and gRPC-style clients,
+	// and with the "Upgrade: h2c" handshake. HTTP/1.1 keeps working on
+	// the same port. Upgraded connections are hijacked from the
+	// http.Server, so Shutdown does not wait for them.
+	H2C bool
+
+	// HTTP2 tunes the HTTP/2 server, e.g. MaxConcurrentStreams and
+	// MaxReadFrameSize. It applies to both TLS and H2C connections.
+	// Nil uses the net/http defaults.
+	HTTP2 *http.HTTP2Config
+}
+
+// DefaultServerConfig returns the ServerConfig used by New.
+//
+// It protects against slow clients (slowloris) while leaving
+// WriteTimeout disabled so long-running handlers are not cut off.
+func DefaultServerConfig() ServerConfig {
+	return ServerConfig{
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       60 * time.Second,
+		IdleTimeout:       120 * time.Second,
+		MaxHeaderBytes:    1 << 20,
+	}
+}
+
+// Option configures an Engine in New.
+type Option func(*Engine)
+
+// WithServerConfig sets the http.Server settings used by Run and its variants.
+func WithServerConfig(config ServerConfig) Option {
+	return func(e *Engine) {
+		e.serverConfig = config
+	}
+}
+
+// Server returns the http.Server used by Run and its variants.
+//
+// The server is created on first use from the ServerConfig. It can be
+// customized further before calling Run, e.g. to set TLSConfig.
+func (e *Engine) Server() *http.Server {
+	if e.server == nil {
+		cfg := e.serverConfig
+		if cfg.ErrorLog == nil {
+			cfg.ErrorLog = slog.NewLogLogger(e.logger.Handler(), slog.LevelError)
+		}
+		e.server = &http.Server{
+			Handler:           http.HandlerFunc(e.handle),
+			ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+			ReadTimeout:       cfg.ReadTimeout,
+			WriteTimeout:      cfg.WriteTimeout,
+			IdleTimeout:       cfg.IdleTimeout,
+			MaxHeaderBytes:    cfg.MaxHeaderBytes,
+			ErrorLog:          cfg.ErrorLog,
+			HTTP2:             cfg.HTTP2,
+		}
+
+		if cfg.H2C {
+			var protocols http.Protocols
+			protocols.SetHTTP1(true)
+			protocols.SetHTTP2(true)
+			protocols.SetUnencryptedHTTP2(true)
+			e.server.Protocols = &protocols
+
+			// net/http serves prior knowledge itself; h2c adds the
+			// upgrade, using the HTTP2 settings of the server
+			e.server.Handler = h2c.NewHandler(e.server.Handler, &http2.Server{})
+		}
+	}
+	return e.server
+}
+
+// RunListener starts the HTTP server on an existing listener.
+//
+// This is useful when the listener is created by a sidecar, a test,
+// or a supervisor that passes sockets to the process.
+//
+// Example:
+//
+//	l, _ := net.Listen("tcp", "127.0.0.1:0")
+//	app.RunListener(l)
+func (e *Engine) RunListener(l net.Listener) error {
+	return e.serve(l, func(srv *http.Server) error {
+		e.logger.Info("server started", "addr", l.Addr().String())
+		return srv.Serve(l)
+	})
+}
+
+// RunUnix starts the HTTP server on a unix domain socket.
+//
+// A stale socket file at socketPath is removed first, and the new
+// socket gets the given file mode (e.g. 0660 to allow a proxy in the
+// same group). The socket file is removed when the server stops, unless
+// the listener was inherited (see InheritedListeners), in which case the
+// file belongs to systemd or to the parent process.
+//
+// Example:
+//
+//	app.RunUnix("/run/myapp.sock", 0660)
+func (e *Engine) RunUnix(socketPath string, mode os.FileMode) error {
+	l, err := e.inheritedListener()
+	if err != nil {
+		return err
+	}
+
+	created := l == nil
+	if created {
+		if err := os.Remove(socketPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+
+		l, err = net.Listen("unix", socketPath)
+		if err != nil {
+			return err
+		}
+
+		if err := os.Chmod(socketPath, mode); err != nil {
+			l.Close()
+			return err
+		}
+	}
+
+	// the socket file belongs to the new process after a graceful restart
+	defer func() {
+		if created && !e.lifecycle.handedOff.Load() {
+			os.Remove(socketPath)
+		}
+	}()
+
+	return e.serve(l, func(srv *http.Server) error {
+		e.logger.Info("server started", "addr", "unix:"+socketPath)
+		return srv.Serve(l)
+	})
+}
+
+// listen returns an inherited listener if one was passed to the process,
+// or creates a new one.
+func (e *Engine) listen(network, addr string) (net.Listener, error) {
+	l, err := e.inheritedListener()
+	if err != nil || l != nil {
+		return l, err
+	}
+	return net.Listen(network, addr)
+}
+
+// serve runs the OnStart hooks, then runs start in the background and
+// waits for it to fail or for a signal. Shutdown signals stop the server
+// gracefully; restart signals hand the listener to a new process first
+// when GracefulRestart is enabled.
+func (e *Engine) serve(l net.Listener, start func(*http.Server) error) error {
+	srv := e.Server()
+	srv.Addr = l.Addr().String()
+
+	if err := e.runStartHooks(); err != nil {
+		l.Close()
+		return err
+	}
+	e.printRoutes()
+
+	// channel to listen to errors
+	serverErr := make(chan error, 1)
+
+	go func() {
+		serverErr <- start(srv)
+	}()
+
+	// tell the parent process we took over its listener
+	notifyParentReady()
+
+	// listen for ctrl+c / sigterm unless disabled
+	var stop, restart chan os.Signal
+	if !e.lifecycle.config.DisableSignals {
+		stop = make(chan os.Signal, 1)
+		signal.Notify(stop, e.lifecycle.config.Signals...)
+		defer signal.Stop(stop)
+
+		// Notify with no signals would relay every signal
+		if e.lifecycle.config.GracefulRestart && len(restartSignals) > 0 {
+			restart = make(chan os.Signal, 1)
+			signal.Notify(restart, restartSignals...)
+			defer signal.Stop(restart)
+		}
+	}
+
+	for {
+		select {
+		case err := <-serverErr:
+			// Shutdown was called programmatically; wait for it to finish
+			if errors.Is(err, http.ErrServerClosed) && e.lifecycle.draining.Load() {
+				return e.Shutdown(context.Background())
+			}
+			return err
+		case sig := <-stop:
+			e.logger.Info("shutting down", "signal", sig.String())
+			return e.Shutdown(context.Background())
+		case sig := <-restart:
+			e.logger.Info("restarting", "signal", sig.String())
+			if err := e.restart(l); err != nil {
+				e.logger.Error("graceful restart failed", "error", err)
+				continue
+			}
+			return e.Shutdown(context.Background())
+		}
+	}
+}
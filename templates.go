@@ -1,8 +1,12 @@
 package mows
 
 import (
+	"errors"
 	"html/template"
+	"io"
 	"io/fs"
+	"maps"
+	"sync"
 	"time"
 )
 
@@ -11,6 +15,7 @@ type TemplateEngine struct {
 	pattern string
 	funcMap template.FuncMap
 	tmpl    *template.Template
+	clones  *sync.Pool
 }
 
 // LoadTemplates loads templates using glob pattern.
@@ -36,10 +41,16 @@ func (e *Engine) LoadTemplates(pattern string) error {
 //   - safeHTML(string) → template.HTML : marks string as safe HTML
 //   - now() → time.Time : current time
 //   - date(time.Time, string) → string : formats time with layout
+//   - csrfToken() → string : CSRF token of the request (CSRF middleware)
+//   - csrfField() → template.HTML : hidden form input with the CSRF token
+//   - cspNonce() → string : Content-Security-Policy nonce (Secure middleware)
+//
+// Request-scoped helpers such as csrfToken are bound by their middleware
+// for each request, and fail when rendering without it.
 //
 // Developers can also add custom functions via Engine.AddTemplateFunc.
 func defaultFuncMap() template.FuncMap {
-	return template.FuncMap{
+	funcs := template.FuncMap{
 		"safeHTML": func(s string) template.HTML {
 			return template.HTML(s)
 		},
@@ -50,6 +61,33 @@ func defaultFuncMap() template.FuncMap {
 			return t.Format(layout)
 		},
 	}
+	maps.Copy(funcs, requestFuncPlaceholders())
+	return funcs
+}
+
+// requestFuncPlaceholders returns the request-scoped template helpers
+// in their unbound state.
+func requestFuncPlaceholders() template.FuncMap {
+	return template.FuncMap{
+		"csrfToken": func() (string, error) {
+			return "", errors.New("mows: csrfToken requires the CSRF middleware")
+		},
+		"csrfField": func() (template.HTML, error) {
+			return "", errors.New("mows: csrfField requires the CSRF middleware")
+		},
+		"cspNonce": func() (string, error) {
+			return "", errors.New("mows: cspNonce requires the Secure middleware with a nonce")
+		},
+	}
+}
+
+// setTemplateFunc binds a request-scoped template helper for the
+// current request.
+func (c *Context) setTemplateFunc(name string, fn any) {
+	if c.templateFuncs == nil {
+		c.templateFuncs = make(template.FuncMap)
+	}
+	c.templateFuncs[name] = fn
 }
 
 // AddTemplateFunc registers a custom helper function for templates.
@@ -86,7 +124,7 @@ func (t *TemplateEngine) load() error {
 		return err
 	}
 
-	t.tmpl = parsed
+	t.setTemplate(parsed)
 	return nil
 }
 
@@ -102,10 +140,48 @@ func (t *TemplateEngine) loadFS(filesystem fs.FS, pattern string) error {
 		return err
 	}
 
-	t.tmpl = parsed
+	t.setTemplate(parsed)
 	return nil
 }
 
+// setTemplate stores freshly parsed templates.
+//
+// The parsed set is never executed itself: requests render clones of
+// it, so request-scoped helpers can be bound without affecting other
+// requests. Clones are pooled to keep html/template's escaping work,
+// done on first execution, from repeating on every render.
+func (t *TemplateEngine) setTemplate(parsed *template.Template) {
+	t.tmpl = parsed
+	t.clones = &sync.Pool{
+		New: func() any {
+			clone, err := parsed.Clone()
+			if err != nil {
+				return err
+			}
+			return clone
+		},
+	}
+}
+
+// execute renders the named template with the request-scoped helpers
+// in funcs.
+func (t *TemplateEngine) execute(w io.Writer, name string, data any, funcs template.FuncMap) error {
+	clones := t.clones
+	item := clones.Get()
+	clone, ok := item.(*template.Template)
+	if !ok {
+		return item.(error)
+	}
+	defer clones.Put(clone)
+
+	// reset every helper so values never leak from a previous request
+	bound := requestFuncPlaceholders()
+	maps.Copy(bound, funcs)
+	clone.Funcs(bound)
+
+	return clone.ExecuteTemplate(w, name, data)
+}
+
 // LoadTemplatesFS loads HTML templates from an embedded filesystem (embed.FS).
 //
 // filesystem: embedded FS containing template files  
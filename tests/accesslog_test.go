@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/saintmili/mows"
+)
+
+func TestAccessLogJSON(t *testing.T) {
+	var out bytes.Buffer
+	app := mows.New()
+	app.Use(mows.AccessLog(mows.AccessLogConfig{
+		Output:    &out,
+		Headers:   []string{"Authorization", "X-Client"},
+		SkipPaths: []string{"/healthz"},
+	}))
+
+	app.GET("/users/:id", func(c *mows.Context) error {
+		return errors.New("boom")
+	})
+	app.GET("/healthz", func(c *mows.Context) error {
+		return c.Text(200, "ok")
+	})
+
+	req := httptest.NewRequest("GET", "/users/42", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("X-Client", "ios")
+	app.ServeHTTP(httptest.NewRecorder(), req)
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/healthz", nil))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 log line got %d: %s", len(lines), out.String())
+	}
+
+	var entry struct {
+		Status  int               `json:"status"`
+		Route   string            `json:"route"`
+		Error   string            `json:"error"`
+		Headers map[string]string `json:"headers"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatal(err)
+	}
+
+	if entry.Status != 400 || entry.Route != "/users/:id" || entry.Error != "boom" {
+		t.Fatalf("unexpected entry: %+v", entry)
+	}
+	if entry.Headers["Authorization"] != "[REDACTED]" || entry.Headers["X-Client"] != "ios" {
+		t.Fatalf("unexpected headers: %v", entry.Headers)
+	}
+}
+
+func TestAccessLogCombined(t *testing.T) {
+	var out bytes.Buffer
+	app := mows.New()
+	app.Use(mows.AccessLog(mows.AccessLogConfig{
+		Format: mows.AccessLogCombined,
+		Output: &out,
+	}))
+
+	app.GET("/hello", func(c *mows.Context) error {
+		return c.Text(200, "world")
+	})
+
+	req := httptest.NewRequest("GET", "/hello", nil)
+	req.Header.Set("User-Agent", "curl/8.0")
+	app.ServeHTTP(httptest.NewRecorder(), req)
+
+	line := out.String()
+	if !strings.HasPrefix(line, "192.0.2.1 - - [") || !strings.Contains(line, `"GET /hello HTTP/1.1" 200 5 "-" "curl/8.0"`) {
+		t.Fatalf("unexpected log line: %s", line)
+	}
+}
+
+func TestContextLogger(t *testing.T) {
+	var out bytes.Buffer
+	app := mows.New()
+	app.SetLogger(slog.New(slog.NewTextHandler(&out, nil)))
+
+	app.GET("/users/:id", func(c *mows.Context) error {
+		c.Logger().Info("loading user")
+		return c.Text(200, "ok")
+	})
+
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/7", nil))
+
+	if !strings.Contains(out.String(), "method=GET route=/users/:id") {
+		t.Fatalf("unexpected log output: %s", out.String())
+	}
+}
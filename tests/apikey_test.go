@@ -0,0 +1,160 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/saintmili/mows"
+)
+
+func TestAPIKey(t *testing.T) {
+	writer, writerHash, _ := mows.GenerateAPIKey("mk_live")
+	reader, readerHash, _ := mows.GenerateAPIKey("mk_live")
+	expired, expiredHash, _ := mows.GenerateAPIKey("mk_live")
+
+	store := mows.NewMemoryKeyStore(
+		mows.APIKeyRecord{ID: "writer", Hash: writerHash, Scopes: []string{"orders:read", "orders:write"}},
+		mows.APIKeyRecord{ID: "reader", Hash: readerHash, Scopes: []string{"orders:read"}},
+		mows.APIKeyRecord{ID: "old", Hash: expiredHash, ExpiresAt: time.Now().Add(-time.Hour)},
+	)
+
+	app := mows.New()
+	api := app.Group("/api", mows.APIKey(mows.APIKeyConfig{Store: store, Query: "api_key"}))
+	api.GET("/orders", mows.RequireScopes("orders:read"), func(c *mows.Context) error {
+		return c.Text(200, c.Principal().(*mows.APIKeyRecord).ID)
+	})
+	api.POST("/orders", mows.RequireScopes("orders:write"), func(c *mows.Context) error {
+		return c.Text(201, "created")
+	})
+
+	send := func(method, target, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w
+	}
+
+	cases := []struct {
+		method, target, key string
+		want                int
+	}{
+		{"GET", "/api/orders", writer, 200},
+		{"POST", "/api/orders", writer, 201},
+		{"GET", "/api/orders", reader, 200},
+		{"POST", "/api/orders", reader, 403},
+		{"GET", "/api/orders?api_key=" + reader, "", 200},
+		{"GET", "/api/orders", expired, 401},
+		{"GET", "/api/orders", "mk_live_unknown", 401},
+		{"GET", "/api/orders", "", 401},
+	}
+	for _, tc := range cases {
+		if w := send(tc.method, tc.target, tc.key); w.Code != tc.want {
+			t.Fatalf("%s %s: expected %d got %d %s", tc.method, tc.target, tc.want, w.Code, w.Body.String())
+		}
+	}
+
+	store.Remove("reader")
+	if w := send("GET", "/api/orders", reader); w.Code != 401 {
+		t.Fatalf("expected revoked key to be rejected, got %d", w.Code)
+	}
+}
+
+func TestGenerateAPIKey(t *testing.T) {
+	format := regexp.MustCompile(`^mk_test_[0-9A-Za-z]{32}$`)
+
+	seen := make(map[string]bool)
+	for range 100 {
+		key, hash, err := mows.GenerateAPIKey("mk_test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !format.MatchString(key) {
+			t.Fatalf("unexpected key format %q", key)
+		}
+		if hash != mows.HashAPIKey(key) || hash == key {
+			t.Fatalf("unexpected hash %q", hash)
+		}
+		if seen[key] {
+			t.Fatalf("duplicate key %q", key)
+		}
+		seen[key] = true
+	}
+}
+
+func TestFileKeyStore(t *testing.T) {
+	first, firstHash, _ := mows.GenerateAPIKey("mk_live")
+	second, secondHash, _ := mows.GenerateAPIKey("mk_live")
+
+	path := filepath.Join(t.TempDir(), "keys.json")
+	write := func(records ...mows.APIKeyRecord) {
+		data, _ := json.Marshal(records)
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(mows.APIKeyRecord{ID: "first", Hash: firstHash, Scopes: []string{"reports:read"}})
+
+	store, err := mows.NewFileKeyStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := mows.New()
+	app.Use(mows.APIKey(mows.APIKeyConfig{Store: store}))
+	app.GET("/reports", mows.RequireScopes("reports:read"), func(c *mows.Context) error {
+		return c.Text(200, "ok")
+	})
+
+	send := func(key string) int {
+		req := httptest.NewRequest("GET", "/reports", nil)
+		req.Header.Set("X-API-Key", key)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := send(first); code != http.StatusOK {
+		t.Fatalf("expected 200 got %d", code)
+	}
+	if code := send(second); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 got %d", code)
+	}
+
+	write(mows.APIKeyRecord{ID: "second", Hash: secondHash, Scopes: []string{"reports:read"}})
+	if err := store.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	if code := send(first); code != http.StatusUnauthorized {
+		t.Fatalf("expected removed key to be rejected, got %d", code)
+	}
+	if code := send(second); code != http.StatusOK {
+		t.Fatalf("expected added key to be accepted, got %d", code)
+	}
+
+	os.WriteFile(path, []byte("not json"), 0600)
+	if err := store.Reload(); err == nil {
+		t.Fatal("expected invalid file to fail")
+	}
+	if code := send(second); code != http.StatusOK {
+		t.Fatalf("expected previous keys to be kept, got %d", code)
+	}
+
+	// a deleted file revokes its keys on the next check
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(1100 * time.Millisecond)
+	if code := send(second); code != http.StatusUnauthorized {
+		t.Fatalf("expected keys of a deleted file to be rejected, got %d", code)
+	}
+}
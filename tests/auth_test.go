@@ -0,0 +1,174 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/saintmili/mows"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestBasicAuth(t *testing.T) {
+	argonHash, err := mows.HashPassword("s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("hunter2"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := mows.New()
+	admin := app.Group("/admin", mows.BasicAuthWithConfig(mows.BasicAuthConfig{
+		Realm: "Admin",
+		Validator: mows.BasicAuthUsers(map[string]string{
+			"alice": argonHash,
+			"bob":   string(bcryptHash),
+			"carol": "plain",
+			"dave":  "",
+		}),
+	}))
+	admin.GET("/me", func(c *mows.Context) error {
+		return c.Text(200, c.Principal().(string))
+	})
+
+	send := func(user, pass string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/admin/me", nil)
+		if user != "" {
+			req.SetBasicAuth(user, pass)
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, tc := range []struct{ user, pass string }{
+		{"alice", "s3cret"},
+		{"bob", "hunter2"},
+	} {
+		w := send(tc.user, tc.pass)
+		if w.Code != 200 || w.Body.String() != tc.user {
+			t.Fatalf("%s: expected 200 got %d %s", tc.user, w.Code, w.Body.String())
+		}
+	}
+
+	for _, tc := range []struct{ user, pass string }{
+		{"", ""},
+		{"alice", "wrong"},
+		{"bob", "wrong"},
+		// unknown hash formats and empty hashes never match
+		{"carol", "plain"},
+		{"dave", ""},
+		{"carol", "plai"},
+		{"mallory", "s3cret"},
+	} {
+		w := send(tc.user, tc.pass)
+		if w.Code != http.StatusUnauthorized {
+			t.Fatalf("%q: expected 401 got %d", tc.user, w.Code)
+		}
+		if got := w.Header().Get("WWW-Authenticate"); got != `Basic realm="Admin", charset="UTF-8"` {
+			t.Fatalf("unexpected challenge %q", got)
+		}
+	}
+}
+
+func TestBasicAuthValidatorError(t *testing.T) {
+	app := mows.New()
+	app.Use(mows.BasicAuth(func(c *mows.Context, username, password string) (any, error) {
+		return nil, mows.NewHTTPError(http.StatusServiceUnavailable, "user store down")
+	}))
+	app.GET("/", func(c *mows.Context) error {
+		return c.Text(200, "ok")
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.SetBasicAuth("alice", "s3cret")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected validator error to pass through, got %d", w.Code)
+	}
+}
+
+type authUser struct {
+	Name string
+}
+
+func TestBearerAuthSources(t *testing.T) {
+	app := mows.New()
+	app.Use(mows.BearerAuthWithConfig(mows.BearerAuthConfig{
+		Query:  "access_token",
+		Cookie: "session",
+		Validator: func(c *mows.Context, token string) (any, error) {
+			if token == "valid" {
+				return &authUser{Name: "alice"}, nil
+			}
+			return nil, nil
+		},
+	}))
+	app.GET("/me", func(c *mows.Context) error {
+		return c.Text(200, c.Principal().(*authUser).Name)
+	})
+
+	send := func(setup func(r *http.Request)) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/me", nil)
+		setup(req)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w
+	}
+
+	sources := map[string]func(r *http.Request){
+		"header": func(r *http.Request) { r.Header.Set("Authorization", "bearer valid") },
+		"query":  func(r *http.Request) { r.URL.RawQuery = "access_token=valid" },
+		"cookie": func(r *http.Request) { r.AddCookie(&http.Cookie{Name: "session", Value: "valid"}) },
+	}
+	for name, setup := range sources {
+		w := send(setup)
+		if w.Code != 200 || w.Body.String() != "alice" {
+			t.Fatalf("%s: expected 200 alice got %d %s", name, w.Code, w.Body.String())
+		}
+	}
+
+	w := send(func(r *http.Request) {})
+	if w.Code != http.StatusUnauthorized || w.Header().Get("WWW-Authenticate") != `Bearer realm="Restricted"` {
+		t.Fatalf("missing token: got %d %q", w.Code, w.Header().Get("WWW-Authenticate"))
+	}
+
+	w = send(func(r *http.Request) { r.Header.Set("Authorization", "Bearer expired") })
+	if w.Code != http.StatusUnauthorized || !strings.Contains(w.Header().Get("WWW-Authenticate"), `error="invalid_token"`) {
+		t.Fatalf("invalid token: got %d %q", w.Code, w.Header().Get("WWW-Authenticate"))
+	}
+}
+
+func TestVerifyPassword(t *testing.T) {
+	hash, err := mows.HashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$") {
+		t.Fatalf("unexpected hash format %q", hash)
+	}
+	if !mows.VerifyPassword(hash, "correct horse") {
+		t.Fatal("expected password to verify")
+	}
+	if mows.VerifyPassword(hash, "battery staple") {
+		t.Fatal("expected wrong password to fail")
+	}
+	if mows.VerifyPassword("$argon2id$v=19$m=0,t=0,p=0$AAAA$AAAA", "x") {
+		t.Fatal("expected malformed hash to fail")
+	}
+	if mows.VerifyPassword("plain", "plain") || mows.VerifyPassword("", "") {
+		t.Fatal("expected unknown hash formats to fail")
+	}
+
+	if !mows.VerifyPlainPassword("plain", "plain") {
+		t.Fatal("expected plain text password to verify")
+	}
+	if mows.VerifyPlainPassword("plain", "wrong") || mows.VerifyPlainPassword("", "") {
+		t.Fatal("expected wrong or empty plain text password to fail")
+	}
+}
@@ -0,0 +1,235 @@
+package tests
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/saintmili/mows"
+)
+
+type member struct {
+	ID    string
+	roles []string
+}
+
+func (m *member) Roles() []string {
+	return m.roles
+}
+
+// memberAuth authenticates "Authorization: <id> <role,role>" headers.
+func memberAuth() mows.Middleware {
+	return mows.BearerAuthWithConfig(mows.BearerAuthConfig{
+		Scheme: "-",
+		Validator: func(c *mows.Context, token string) (any, error) {
+			id, roles, _ := strings.Cut(token, " ")
+			return &member{ID: id, roles: strings.Split(roles, ",")}, nil
+		},
+	})
+}
+
+func TestAuthorizeRBAC(t *testing.T) {
+	rbac := mows.NewRBAC().
+		Grant("viewer", "orders:read").
+		Grant("editor", "orders:write").
+		Inherit("editor", "viewer").
+		Inherit("admin", "editor").
+		Grant("ops", "reports:*")
+
+	var denied *mows.AuthorizationError
+	app := mows.New()
+	app.SetErrorHandler(func(c *mows.Context, err error) {
+		var httpErr *mows.HTTPError
+		errors.As(err, &httpErr)
+		if !errors.As(err, &denied) {
+			denied = nil
+		}
+		c.JSON(httpErr.Code, map[string]string{"error": err.Error()})
+	})
+
+	api := app.Group("/api", memberAuth(), mows.Authorize(rbac))
+	api.GET("/public", func(c *mows.Context) error {
+		return c.Text(200, "ok")
+	})
+
+	orders := api.Group("/orders").Require("orders:read")
+	orders.GET("", func(c *mows.Context) error {
+		return c.Text(200, "ok")
+	})
+	orders.Require("orders:write")
+	orders.POST("", func(c *mows.Context) error {
+		return c.Text(201, "ok")
+	})
+
+	api.Group("/admin").Require("admin").GET("/stats", func(c *mows.Context) error {
+		return c.Text(200, "ok")
+	})
+	api.Group("/reports").Require("reports:daily").GET("", func(c *mows.Context) error {
+		return c.Text(200, "ok")
+	})
+
+	send := func(method, path, auth string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", auth)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	cases := []struct {
+		method, path, auth string
+		want               int
+	}{
+		{"GET", "/api/public", "u1 none", 200},
+		{"GET", "/api/orders", "u1 viewer", 200},
+		{"POST", "/api/orders", "u1 viewer", 403},
+		{"POST", "/api/orders", "u1 editor", 201},
+		{"GET", "/api/orders", "u1 admin", 200},
+		{"GET", "/api/admin/stats", "u1 editor", 403},
+		{"GET", "/api/admin/stats", "u1 admin", 200},
+		{"GET", "/api/reports", "u1 ops", 200},
+		{"GET", "/api/reports", "u1 viewer", 403},
+	}
+	for _, tc := range cases {
+		if got := send(tc.method, tc.path, tc.auth); got != tc.want {
+			t.Fatalf("%s %s as %q: expected %d got %d", tc.method, tc.path, tc.auth, tc.want, got)
+		}
+	}
+
+	send("POST", "/api/orders", "u1 viewer")
+	if denied == nil || denied.Requirement != "orders:write" {
+		t.Fatalf("expected typed authorization error, got %v", denied)
+	}
+}
+
+func TestAuthorizeABAC(t *testing.T) {
+	abac := mows.NewABAC().
+		Rule("profile:edit", func(a mows.Attributes) bool {
+			return a.Principal.(*member).ID == a.Params["id"]
+		}).
+		Rule("profile:edit", func(a mows.Attributes) bool {
+			return slices.Contains(a.Principal.(*member).Roles(), "support") && a.Method == http.MethodPut
+		})
+
+	app := mows.New()
+	users := app.Group("/users", memberAuth(), mows.Authorize(mows.AnyPolicy(mows.NewRBAC(), abac))).Require("profile:edit")
+	users.PUT("/:id", func(c *mows.Context) error {
+		return c.Text(200, "updated")
+	})
+	users.DELETE("/:id", func(c *mows.Context) error {
+		return c.Text(200, "deleted")
+	})
+
+	send := func(method, path, auth string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", auth)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	cases := []struct {
+		method, path, auth string
+		want               int
+	}{
+		{"PUT", "/users/u1", "u1 none", 200},
+		{"PUT", "/users/u2", "u1 none", 403},
+		{"PUT", "/users/u2", "u1 support", 200},
+		{"DELETE", "/users/u2", "u1 support", 403},
+		{"DELETE", "/users/u1", "u1 none", 200},
+	}
+	for _, tc := range cases {
+		if got := send(tc.method, tc.path, tc.auth); got != tc.want {
+			t.Fatalf("%s %s as %q: expected %d got %d", tc.method, tc.path, tc.auth, tc.want, got)
+		}
+	}
+}
+
+func TestAuthorizeFailsClosed(t *testing.T) {
+	app := mows.New()
+
+	// requirements without an Authorize middleware must not leave the route open
+	app.Group("/admin", memberAuth()).Require("admin").GET("/stats", func(c *mows.Context) error {
+		return c.Text(200, "secret")
+	})
+
+	// Authorize before authentication sees no principal
+	app.Group("/ops", mows.Authorize(mows.NewRBAC()), memberAuth()).Require("ops").GET("/stats", func(c *mows.Context) error {
+		return c.Text(200, "secret")
+	})
+
+	for path, want := range map[string]int{"/admin/stats": 500, "/ops/stats": 401} {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Authorization", "u1 admin,ops")
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		if w.Code != want || w.Body.String() == "secret" {
+			t.Fatalf("%s: expected %d got %d %s", path, want, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestRouteGuards(t *testing.T) {
+	rbac := mows.NewRBAC()
+	app := mows.New()
+	app.GET("/health", func(c *mows.Context) error { return nil })
+
+	admin := app.Group("/admin", mows.JWT(mows.JWTConfig{Keys: mows.StaticKeySet{"": []byte("k")}}), mows.Authorize(rbac)).Require("admin")
+	admin.GET("/users", func(c *mows.Context) error { return nil })
+	admin.DELETE("/users/:id", func(c *mows.Context) error { return nil })
+
+	guards := app.RouteGuards()
+	if len(guards) != 3 {
+		t.Fatalf("expected 3 routes got %d", len(guards))
+	}
+
+	for _, g := range guards {
+		if g.Pattern == "/health" {
+			if len(g.Guards) != 0 || len(g.Requirements) != 0 {
+				t.Fatalf("expected /health to be public, got %+v", g)
+			}
+			continue
+		}
+		if !strings.HasPrefix(g.Pattern, "/admin") {
+			t.Fatalf("unexpected route %+v", g)
+		}
+		if !slices.Equal(g.Guards, []string{"mows.JWT", "mows.Authorize"}) || !slices.Equal(g.Requirements, []string{"admin"}) {
+			t.Fatalf("unexpected guards for %s %s: %+v", g.Method, g.Pattern, g)
+		}
+		if len(g.Policies) != 1 || g.Policies[0] != mows.Policy(rbac) || g.MissingAuthorize {
+			t.Fatalf("expected %s %s to be checked by the rbac policy, got %+v", g.Method, g.Pattern, g)
+		}
+	}
+}
+
+func TestRouteGuardsMissingAuthorize(t *testing.T) {
+	app := mows.New()
+	reports := app.Group("/reports", mows.JWT(mows.JWTConfig{Keys: mows.StaticKeySet{"": []byte("k")}})).Require("reports:read")
+	reports.GET("", func(c *mows.Context) error { return nil })
+
+	guards := app.RouteGuards()
+	if len(guards) != 1 || !guards[0].MissingAuthorize || guards[0].Policies != nil {
+		t.Fatalf("expected a route with requirements but no Authorize to be flagged, got %+v", guards)
+	}
+}
+
+func TestRouteGuardsDistinctPolicies(t *testing.T) {
+	admins := mows.NewRBAC()
+	staff := mows.NewRBAC()
+	app := mows.New()
+	app.Group("/admin", mows.Authorize(admins)).GET("", func(c *mows.Context) error { return nil })
+	app.Group("/staff", mows.Authorize(staff)).GET("", func(c *mows.Context) error { return nil })
+
+	for _, g := range app.RouteGuards() {
+		want := mows.Policy(admins)
+		if g.Pattern == "/staff" {
+			want = staff
+		}
+		if len(g.Policies) != 1 || g.Policies[0] != want {
+			t.Fatalf("expected %s to report its own policy, got %+v", g.Pattern, g.Policies)
+		}
+	}
+}
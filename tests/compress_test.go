@@ -0,0 +1,221 @@
+package tests
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/saintmili/mows"
+)
+
+func TestCompressGzip(t *testing.T) {
+	app := mows.New()
+	app.Use(mows.Compress(mows.CompressConfig{}))
+
+	payload := strings.Repeat("mows", 1024)
+	app.GET("/big", func(c *mows.Context) error {
+		return c.JSON(200, map[string]string{"data": payload})
+	})
+
+	req := httptest.NewRequest("GET", "/big", nil)
+	req.Header.Set("Accept-Encoding", "deflate;q=0.5, gzip")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	if w.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("expected gzip encoding got %q", w.Header().Get("Content-Encoding"))
+	}
+	if w.Header().Get("Vary") != "Accept-Encoding" {
+		t.Fatalf("expected Vary header got %q", w.Header().Get("Vary"))
+	}
+
+	zr, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(body), payload) {
+		t.Fatal("decompressed body does not match")
+	}
+}
+
+func TestCompressSkipsSmallResponses(t *testing.T) {
+	app := mows.New()
+	app.Use(mows.Compress(mows.CompressConfig{}))
+
+	app.GET("/small", func(c *mows.Context) error {
+		return c.Text(200, "tiny")
+	})
+
+	req := httptest.NewRequest("GET", "/small", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	if w.Header().Get("Content-Encoding") != "" {
+		t.Fatal("small response should not be compressed")
+	}
+	if w.Body.String() != "tiny" {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	}
+}
+
+// upperEncoder is a custom Encoder that upper-cases the body, so tests
+// can tell it ran.
+type upperEncoder struct{}
+
+func (upperEncoder) Encoding() string { return "x-upper" }
+
+func (upperEncoder) NewWriter(w io.Writer) (mows.EncoderWriter, error) {
+	return &upperWriter{w: w}, nil
+}
+
+type upperWriter struct{ w io.Writer }
+
+func (u *upperWriter) Write(b []byte) (int, error) {
+	return u.w.Write(bytes.ToUpper(b))
+}
+func (u *upperWriter) Close() error      { return nil }
+func (u *upperWriter) Flush() error      { return nil }
+func (u *upperWriter) Reset(w io.Writer) { u.w = w }
+
+func compressApp(config mows.CompressConfig, m ...mows.Middleware) *mows.Engine {
+	app := mows.New()
+	for _, mw := range m {
+		app.Use(mw)
+	}
+	app.Use(mows.Compress(config))
+
+	app.GET("/big", func(c *mows.Context) error {
+		c.Writer.Header().Set("Content-Type", "text/plain")
+		return c.Text(200, strings.Repeat("mows", 1024))
+	})
+	app.GET("/chunks", func(c *mows.Context) error {
+		c.Writer.Header().Set("Content-Type", "text/plain")
+		for range 4 {
+			if _, err := c.Writer.Write([]byte(strings.Repeat("a", 300))); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return app
+}
+
+func compressGet(app *mows.Engine, path, acceptEncoding string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", path, nil)
+	req.Header.Set("Accept-Encoding", acceptEncoding)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	return w
+}
+
+func TestCompressEncoders(t *testing.T) {
+	payload := strings.Repeat("mows", 1024)
+
+	w := compressGet(compressApp(mows.CompressConfig{}), "/big", "gzip;q=0.5, deflate", nil)
+	if w.Header().Get("Content-Encoding") != "deflate" {
+		t.Fatalf("expected deflate encoding got %q", w.Header().Get("Content-Encoding"))
+	}
+	zr, err := zlib.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, _ := io.ReadAll(zr); string(body) != payload {
+		t.Fatal("deflated body does not match")
+	}
+
+	app := compressApp(mows.CompressConfig{Encoders: []mows.Encoder{upperEncoder{}, mows.GzipEncoder(0)}})
+	w = compressGet(app, "/big", "*", nil)
+	if w.Header().Get("Content-Encoding") != "x-upper" || w.Body.String() != strings.ToUpper(payload) {
+		t.Fatalf("expected custom encoder to be preferred, got %q", w.Header().Get("Content-Encoding"))
+	}
+	if w := compressGet(app, "/big", "br", nil); w.Header().Get("Content-Encoding") != "" || w.Body.String() != payload {
+		t.Fatalf("expected identity for unsupported encoding, got %q", w.Header().Get("Content-Encoding"))
+	}
+}
+
+func TestCompressSkipsRangeAndHead(t *testing.T) {
+	payload := strings.Repeat("mows", 1024)
+
+	w := compressGet(compressApp(mows.CompressConfig{}), "/big", "gzip", map[string]string{"Range": "bytes=0-9"})
+	if w.Header().Get("Content-Encoding") != "" || w.Body.String() != payload {
+		t.Fatal("range requests should not be compressed")
+	}
+
+	// the router has no HEAD routes, so rewrite the method before Compress
+	head := func(next mows.HandlerFunc) mows.HandlerFunc {
+		return func(c *mows.Context) error {
+			c.Request.Method = http.MethodHead
+			return next(c)
+		}
+	}
+	w = compressGet(compressApp(mows.CompressConfig{}, head), "/big", "gzip", nil)
+	if w.Header().Get("Content-Encoding") != "" {
+		t.Fatal("HEAD requests should not be compressed")
+	}
+	if w.Header().Get("Vary") != "Accept-Encoding" {
+		t.Fatalf("expected Vary header on skipped responses got %q", w.Header().Get("Vary"))
+	}
+}
+
+func TestCompressVary(t *testing.T) {
+	app := compressApp(mows.CompressConfig{})
+	for _, acceptEncoding := range []string{"gzip", ""} {
+		w := compressGet(app, "/big", acceptEncoding, nil)
+		if vary := w.Header().Values("Vary"); len(vary) != 1 || vary[0] != "Accept-Encoding" {
+			t.Fatalf("Accept-Encoding %q: expected Vary: Accept-Encoding got %v", acceptEncoding, vary)
+		}
+	}
+}
+
+func TestCompressAcrossWrites(t *testing.T) {
+	app := compressApp(mows.CompressConfig{})
+
+	w := compressGet(app, "/chunks", "gzip", nil)
+	if w.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("expected gzip once MinLength is crossed got %q", w.Header().Get("Content-Encoding"))
+	}
+	zr, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, _ := io.ReadAll(zr); string(body) != strings.Repeat("a", 1200) {
+		t.Fatalf("unexpected body length %d", len(body))
+	}
+
+	app = compressApp(mows.CompressConfig{MinLength: 2000})
+	if w := compressGet(app, "/chunks", "gzip", nil); w.Header().Get("Content-Encoding") != "" || w.Body.Len() != 1200 {
+		t.Fatal("response below MinLength should not be compressed")
+	}
+}
+
+func TestCompressFlushBeforeWrite(t *testing.T) {
+	app := mows.New()
+	app.Use(mows.Compress(mows.CompressConfig{}))
+	app.GET("/events", func(c *mows.Context) error {
+		c.Writer.Flush()
+		c.Writer.Header().Set("Content-Type", "text/event-stream")
+		if _, err := c.Writer.Write([]byte("data: " + strings.Repeat("a", 1200) + "\n\n")); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		return nil
+	})
+
+	w := compressGet(app, "/events", "gzip", nil)
+	if ct := w.Result().Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected the stream's Content-Type got %q", ct)
+	}
+}
@@ -0,0 +1,215 @@
+package tests
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/saintmili/mows"
+)
+
+func csrfApp(t *testing.T, config mows.CSRFConfig) *mows.Engine {
+	t.Helper()
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "form.html"), []byte(`<form method="post">{{ csrfField }}</form><meta content="{{ csrfToken }}">`), 0600)
+
+	app := mows.New()
+	if err := app.LoadTemplates(filepath.Join(dir, "*.html")); err != nil {
+		t.Fatal(err)
+	}
+	app.Use(mows.CSRF(config))
+
+	app.GET("/form", func(c *mows.Context) error {
+		return c.HTML(200, "form.html", nil)
+	})
+	app.POST("/form", func(c *mows.Context) error {
+		return c.Text(200, "saved")
+	})
+	app.POST("/webhooks/stripe", func(c *mows.Context) error {
+		return c.Text(200, "received")
+	})
+	return app
+}
+
+var csrfInput = regexp.MustCompile(`<input type="hidden" name="csrf_token" value="([^"]+)">`)
+
+func TestCSRFDoubleSubmit(t *testing.T) {
+	app := csrfApp(t, mows.CSRFConfig{Exempt: []string{"/webhooks/*"}})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "http://example.com/form", nil))
+
+	match := csrfInput.FindStringSubmatch(w.Body.String())
+	if match == nil {
+		t.Fatalf("expected csrf field in %s", w.Body.String())
+	}
+	token := match[1]
+	if !strings.Contains(w.Body.String(), `content="`+token+`"`) {
+		t.Fatalf("expected csrfToken to match the field, got %s", w.Body.String())
+	}
+
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Value != token || !cookies[0].HttpOnly {
+		t.Fatalf("unexpected cookies %+v", cookies)
+	}
+	cookie := cookies[0]
+
+	post := func(form url.Values, setup func(r *http.Request)) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "http://example.com/form", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(cookie)
+		setup(req)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w
+	}
+
+	none := func(r *http.Request) {}
+	if w := post(url.Values{"csrf_token": {token}}, none); w.Code != 200 {
+		t.Fatalf("expected form token to be accepted, got %d", w.Code)
+	}
+	if w := post(nil, func(r *http.Request) { r.Header.Set("X-CSRF-Token", token) }); w.Code != 200 {
+		t.Fatalf("expected header token to be accepted, got %d", w.Code)
+	}
+	if w := post(url.Values{"csrf_token": {token}}, func(r *http.Request) { r.Header.Set("Origin", "http://example.com") }); w.Code != 200 {
+		t.Fatalf("expected same origin to be accepted, got %d", w.Code)
+	}
+
+	if w := post(nil, none); w.Code != http.StatusForbidden {
+		t.Fatalf("expected missing token to be rejected, got %d", w.Code)
+	}
+	if w := post(url.Values{"csrf_token": {"forged"}}, none); w.Code != http.StatusForbidden {
+		t.Fatalf("expected wrong token to be rejected, got %d", w.Code)
+	}
+	if w := post(url.Values{"csrf_token": {token}}, func(r *http.Request) { r.Header.Set("Origin", "https://evil.example") }); w.Code != http.StatusForbidden {
+		t.Fatalf("expected cross origin to be rejected, got %d", w.Code)
+	}
+	if w := post(url.Values{"csrf_token": {token}}, func(r *http.Request) { r.Header.Set("Referer", "https://evil.example/page") }); w.Code != http.StatusForbidden {
+		t.Fatalf("expected cross origin referer to be rejected, got %d", w.Code)
+	}
+
+	req := httptest.NewRequest("POST", "http://example.com/webhooks/stripe", nil)
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if w.Code != 200 {
+		t.Fatalf("expected exempt route to pass, got %d", w.Code)
+	}
+}
+
+func TestCSRFSignedSessionTokens(t *testing.T) {
+	app := csrfApp(t, mows.CSRFConfig{
+		Secret: []byte("0123456789abcdef"),
+		Session: func(c *mows.Context) string {
+			return c.Request.Header.Get("X-Session")
+		},
+	})
+
+	get := httptest.NewRequest("GET", "/form", nil)
+	get.Header.Set("X-Session", "alice")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, get)
+	cookie := w.Result().Cookies()[0]
+
+	post := func(session string) int {
+		req := httptest.NewRequest("POST", "/form", nil)
+		req.Header.Set("X-Session", session)
+		req.Header.Set("X-CSRF-Token", cookie.Value)
+		req.AddCookie(cookie)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := post("alice"); code != 200 {
+		t.Fatalf("expected token to be valid for its session, got %d", code)
+	}
+	if code := post("mallory"); code != http.StatusForbidden {
+		t.Fatalf("expected token of another session to be rejected, got %d", code)
+	}
+}
+
+func TestCSRFSynchronizer(t *testing.T) {
+	var rejected error
+	store := mows.NewMemoryCSRFStore()
+	app := csrfApp(t, mows.CSRFConfig{
+		Mode:  mows.CSRFSynchronizer,
+		Store: store,
+		Session: func(c *mows.Context) string {
+			return c.Request.Header.Get("X-Session")
+		},
+	})
+	app.SetErrorHandler(func(c *mows.Context, err error) {
+		rejected = err
+		c.Text(403, "forbidden")
+	})
+
+	get := httptest.NewRequest("GET", "/form", nil)
+	get.Header.Set("X-Session", "s1")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, get)
+
+	if len(w.Result().Cookies()) != 0 {
+		t.Fatal("synchronizer mode should not set cookies")
+	}
+	token := csrfInput.FindStringSubmatch(w.Body.String())[1]
+
+	post := func(session, token string) int {
+		req := httptest.NewRequest("POST", "/form", nil)
+		req.Header.Set("X-Session", session)
+		req.Header.Set("X-CSRF-Token", token)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := post("s1", token); code != 200 {
+		t.Fatalf("expected 200 got %d", code)
+	}
+	if code := post("s2", token); code != 403 || !errors.Is(rejected, mows.ErrCSRFToken) {
+		t.Fatalf("expected token of another session to be rejected, got %d %v", code, rejected)
+	}
+
+	store.Delete("s1")
+	if code := post("s1", token); code != 403 {
+		t.Fatalf("expected deleted token to be rejected, got %d", code)
+	}
+}
+
+func TestCSRFTemplateFuncsRequireMiddleware(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "form.html"), []byte(`{{ csrfField }}`), 0600)
+
+	app := mows.New()
+	if err := app.LoadTemplates(filepath.Join(dir, "*.html")); err != nil {
+		t.Fatal(err)
+	}
+
+	var renderErr error
+	app.GET("/", func(c *mows.Context) error {
+		renderErr = c.HTML(200, "form.html", nil)
+		return nil
+	})
+	app.Group("/protected", mows.CSRF(mows.CSRFConfig{})).GET("", func(c *mows.Context) error {
+		return c.HTML(200, "form.html", nil)
+	})
+
+	// render with the middleware first so a pooled template has a bound token
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/protected", nil))
+	if !csrfInput.MatchString(w.Body.String()) {
+		t.Fatalf("expected csrf field, got %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if renderErr == nil || csrfInput.MatchString(w.Body.String()) {
+		t.Fatalf("expected csrfField to fail without the middleware, got %v %s", renderErr, w.Body.String())
+	}
+}
@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/saintmili/mows"
+)
+
+func gzipBody(t *testing.T, s string) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestDecompressGzipJSON(t *testing.T) {
+	app := mows.New()
+	app.Use(mows.Decompress(mows.DecompressConfig{}))
+
+	app.POST("/logs", func(c *mows.Context) error {
+		var body struct {
+			Name string `json:"name"`
+		}
+		if err := c.BindJSON(&body); err != nil {
+			return err
+		}
+		return c.Text(200, body.Name)
+	})
+
+	req := httptest.NewRequest("POST", "/logs", gzipBody(t, `{"name":"mows"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	if w.Body.String() != "mows" {
+		t.Fatalf("expected mows got %s", w.Body.String())
+	}
+}
+
+func TestDecompressLimit(t *testing.T) {
+	app := mows.New()
+	app.Use(mows.Decompress(mows.DecompressConfig{MaxSize: 64}))
+
+	app.POST("/logs", func(c *mows.Context) error {
+		var body map[string]string
+		return c.BindJSON(&body)
+	})
+
+	payload := `{"name":"` + strings.Repeat("a", 1024) + `"}`
+	req := httptest.NewRequest("POST", "/logs", gzipBody(t, payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	if w.Code != 413 {
+		t.Fatalf("expected 413 got %d", w.Code)
+	}
+}
+
+func TestDecompressUnsupportedEncoding(t *testing.T) {
+	app := mows.New()
+	app.Use(mows.Decompress(mows.DecompressConfig{}))
+
+	app.POST("/logs", func(c *mows.Context) error {
+		return c.Text(200, "ok")
+	})
+
+	req := httptest.NewRequest("POST", "/logs", strings.NewReader("data"))
+	req.Header.Set("Content-Encoding", "br")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	if w.Code != 415 {
+		t.Fatalf("expected 415 got %d", w.Code)
+	}
+}
@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/saintmili/mows"
+)
+
+func docsApp(devMode bool, config mows.DocsConfig) *mows.Engine {
+	app := mows.New()
+	app.DevMode(devMode)
+	app.DocsWithConfig(config)
+	app.GET("/users/:id", func(c *mows.Context) error {
+		return c.JSON(200, map[string]string{"id": c.Param("id")})
+	}).Summary("Get a user")
+	return app
+}
+
+func docsGet(app *mows.Engine, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+	return w
+}
+
+func TestDocsInDevMode(t *testing.T) {
+	app := docsApp(true, mows.DocsConfig{Path: "/docs"})
+
+	if w := docsGet(app, "/docs"); w.Code != 301 || w.Header().Get("Location") != "/docs/" {
+		t.Fatalf("expected redirect to /docs/, got %d %q", w.Code, w.Header().Get("Location"))
+	}
+
+	w := docsGet(app, "/docs/")
+	if w.Code != 200 || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") {
+		t.Fatalf("expected index page, got %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	if strings.Contains(w.Body.String(), "http://") || strings.Contains(w.Body.String(), "https://") {
+		t.Fatal("expected no external resources in the index page")
+	}
+	for _, asset := range []string{"docs.js", "docs.css"} {
+		if !strings.Contains(w.Body.String(), `"`+asset+`"`) {
+			t.Fatalf("expected index to reference %s", asset)
+		}
+		if w := docsGet(app, "/docs/"+asset); w.Code != 200 || w.Body.Len() == 0 {
+			t.Fatalf("expected %s to be served, got %d", asset, w.Code)
+		}
+	}
+	if ct := docsGet(app, "/docs/docs.js").Header().Get("Content-Type"); !strings.Contains(ct, "javascript") {
+		t.Fatalf("unexpected script content type %q", ct)
+	}
+
+	w = docsGet(app, "/docs/openapi.json")
+	var doc mows.OpenAPIDocument
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if op := doc.Paths["/users/{id}"]["get"]; op == nil || op.Summary != "Get a user" {
+		t.Fatalf("expected documented route in spec, got %+v", doc.Paths)
+	}
+	if len(doc.Paths) != 1 {
+		t.Fatalf("expected docs routes to be hidden from the spec, got %+v", doc.Paths)
+	}
+}
+
+func TestDocsDisabledOutsideDevMode(t *testing.T) {
+	app := docsApp(false, mows.DocsConfig{})
+	for _, path := range []string{"/docs", "/docs/", "/docs/docs.js", "/docs/openapi.json"} {
+		if w := docsGet(app, path); w.Code != 404 {
+			t.Fatalf("%s: expected 404 got %d", path, w.Code)
+		}
+	}
+
+	app = docsApp(false, mows.DocsConfig{Enabled: true})
+	if w := docsGet(app, "/docs/"); w.Code != 200 {
+		t.Fatalf("expected explicitly enabled docs, got %d", w.Code)
+	}
+}
@@ -0,0 +1,145 @@
+//go:build unix
+
+package tests
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/saintmili/mows"
+)
+
+// TestInheritedListenerHelper is run in a child process by
+// TestSocketActivation; it serves on the inherited listener.
+func TestInheritedListenerHelper(t *testing.T) {
+	if os.Getenv("MOWS_TEST_HELPER") != "1" {
+		t.Skip("helper process")
+	}
+
+	app := mows.New(mows.WithShutdownConfig(mows.ShutdownConfig{DisableSignals: true}))
+	app.GET("/ping", func(c *mows.Context) error {
+		return c.Text(200, "inherited")
+	})
+
+	// the address is ignored because a listener was inherited
+	app.Run("127.0.0.1:1")
+}
+
+func TestSocketActivation(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	f, err := l.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	cmd := exec.Command(os.Args[0], "-test.run=TestInheritedListenerHelper")
+	cmd.Env = append(os.Environ(), "MOWS_TEST_HELPER=1", "LISTEN_FDS=1")
+	cmd.ExtraFiles = []*os.File{f}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}()
+
+	// stop accepting in this process so the child serves every request
+	l.Close()
+
+	var body []byte
+	for i := 0; i < 100; i++ {
+		res, err := http.Get("http://" + l.Addr().String() + "/ping")
+		if err == nil {
+			body, _ = io.ReadAll(res.Body)
+			res.Body.Close()
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if string(body) != "inherited" {
+		t.Fatalf("expected inherited got %q", body)
+	}
+}
+
+// TestInheritedUnixListenerHelper is run in a child process by
+// TestSocketActivationUnix; it serves on the inherited unix listener
+// until /stop is requested.
+func TestInheritedUnixListenerHelper(t *testing.T) {
+	if os.Getenv("MOWS_TEST_HELPER") != "unix" {
+		t.Skip("helper process")
+	}
+
+	app := mows.New(mows.WithShutdownConfig(mows.ShutdownConfig{DisableSignals: true}))
+	app.GET("/stop", func(c *mows.Context) error {
+		go app.Shutdown(context.Background())
+		return c.Text(200, "stopping")
+	})
+
+	app.RunUnix(os.Getenv("MOWS_TEST_SOCKET"), 0600)
+}
+
+func TestSocketActivationUnix(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "mows.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// like systemd, keep the socket file when this copy is closed
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+	defer l.Close()
+
+	f, err := l.(*net.UnixListener).File()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	cmd := exec.Command(os.Args[0], "-test.run=TestInheritedUnixListenerHelper")
+	cmd.Env = append(os.Environ(), "MOWS_TEST_HELPER=unix", "MOWS_TEST_SOCKET="+socket, "LISTEN_FDS=1")
+	cmd.ExtraFiles = []*os.File{f}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer cmd.Process.Kill()
+
+	l.Close()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socket)
+		},
+	}}
+	var res *http.Response
+	for i := 0; i < 100; i++ {
+		if res, err = client.Get("http://unix/stop"); err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if err := cmd.Wait(); err != nil {
+		t.Fatalf("helper failed: %v", err)
+	}
+	if _, err := os.Stat(socket); err != nil {
+		t.Fatalf("expected inherited socket file to be kept: %v", err)
+	}
+}
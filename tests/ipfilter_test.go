@@ -0,0 +1,58 @@
+package tests
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/saintmili/mows"
+)
+
+func TestIPFilterGroup(t *testing.T) {
+	app := mows.New()
+	app.SetTrustedProxies([]string{"10.0.0.1"})
+
+	vpn, err := mows.NewIPAccessList([]string{"192.168.0.0/16", "2001:db8::/32"}, []string{"192.168.66.0/24"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	admin := app.Group("/admin", mows.IPFilter(mows.IPFilterConfig{List: vpn}))
+	admin.GET("/stats", func(c *mows.Context) error {
+		return c.Text(200, "ok")
+	})
+
+	send := func(remote, forwarded string) int {
+		req := httptest.NewRequest("GET", "/admin/stats", nil)
+		req.RemoteAddr = remote
+		if forwarded != "" {
+			req.Header.Set("X-Forwarded-For", forwarded)
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	cases := []struct {
+		remote, forwarded string
+		want              int
+	}{
+		{"192.168.1.10:1000", "", 200},
+		{"[2001:db8::42]:1000", "", 200},
+		{"192.168.66.5:1000", "", 403},
+		{"203.0.113.9:1000", "", 403},
+		{"10.0.0.1:1000", "192.168.1.10", 200},
+		{"10.0.0.1:1000", "203.0.113.9", 403},
+	}
+	for _, tc := range cases {
+		if got := send(tc.remote, tc.forwarded); got != tc.want {
+			t.Fatalf("%s (%s): expected %d got %d", tc.remote, tc.forwarded, tc.want, got)
+		}
+	}
+
+	if err := vpn.Update([]string{"203.0.113.0/24"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := send("203.0.113.9:1000", ""); got != 200 {
+		t.Fatalf("expected updated rules to allow, got %d", got)
+	}
+}
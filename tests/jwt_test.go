@@ -0,0 +1,355 @@
+package tests
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/saintmili/mows"
+)
+
+type orderClaims struct {
+	mows.RegisteredClaims
+	Role string `json:"role"`
+}
+
+func jwtApp(config mows.JWTConfig) *mows.Engine {
+	app := mows.New()
+	app.Use(mows.JWT(config))
+	app.GET("/me", func(c *mows.Context) error {
+		claims, err := mows.Claims[orderClaims](c)
+		if err != nil {
+			return err
+		}
+		return c.Text(200, claims.Subject+":"+claims.Role)
+	})
+	return app
+}
+
+func sendToken(app *mows.Engine, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", "/me", nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	return w
+}
+
+func validClaims() orderClaims {
+	now := time.Now()
+	return orderClaims{
+		RegisteredClaims: mows.RegisteredClaims{
+			Issuer:    "auth",
+			Subject:   "alice",
+			Audience:  mows.Audience{"orders"},
+			IssuedAt:  mows.NewNumericDate(now),
+			ExpiresAt: mows.NewNumericDate(now.Add(time.Hour)),
+		},
+		Role: "admin",
+	}
+}
+
+func TestJWTAlgorithms(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	secret := []byte("0123456789abcdef0123456789abcdef")
+
+	keys := mows.StaticKeySet{
+		"hs": secret,
+		"rs": &rsaKey.PublicKey,
+		"es": &ecKey.PublicKey,
+		"ed": edKey.Public(),
+	}
+	app := jwtApp(mows.JWTConfig{Keys: keys, Issuer: "auth", Audience: "orders"})
+
+	signers := []struct {
+		alg, kid string
+		key      any
+	}{
+		{mows.HS256, "hs", secret},
+		{mows.RS256, "rs", rsaKey},
+		{mows.ES256, "es", ecKey},
+		{mows.EdDSA, "ed", edKey},
+	}
+	for _, s := range signers {
+		signer, err := mows.NewJWTSigner(s.alg, s.kid, s.key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		token, err := signer.Sign(validClaims())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		w := sendToken(app, token)
+		if w.Code != 200 || w.Body.String() != "alice:admin" {
+			t.Fatalf("%s: expected 200 got %d %s", s.alg, w.Code, w.Body.String())
+		}
+
+		// tamper with the payload
+		parts := strings.Split(token, ".")
+		forged, _ := mows.NewJWTSigner(mows.HS256, "", []byte("other"))
+		other, _ := forged.Sign(orderClaims{Role: "root"})
+		parts[1] = strings.Split(other, ".")[1]
+		if w := sendToken(app, strings.Join(parts, ".")); w.Code != http.StatusUnauthorized {
+			t.Fatalf("%s: expected tampered token to be rejected, got %d", s.alg, w.Code)
+		}
+	}
+}
+
+func TestJWTAlgorithmConfusion(t *testing.T) {
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	pub := edKey.Public().(ed25519.PublicKey)
+
+	app := jwtApp(mows.JWTConfig{Keys: mows.StaticKeySet{"ed": pub}})
+
+	// an attacker signs with HS256 using the public key as secret
+	signer, _ := mows.NewJWTSigner(mows.HS256, "ed", []byte(pub))
+	token, _ := signer.Sign(validClaims())
+
+	w := sendToken(app, token)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 got %d", w.Code)
+	}
+
+	// unsigned tokens are never accepted
+	none := "eyJhbGciOiJub25lIn0." + strings.Split(token, ".")[1] + "."
+	if w := sendToken(app, none); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected alg none to be rejected, got %d", w.Code)
+	}
+}
+
+func TestJWTClaimsValidation(t *testing.T) {
+	secret := []byte("secret")
+	signer, _ := mows.NewJWTSigner(mows.HS256, "", secret)
+	app := jwtApp(mows.JWTConfig{
+		Keys:      mows.StaticKeySet{"v1": secret},
+		Issuer:    "auth",
+		Audience:  "orders",
+		ClockSkew: 30 * time.Second,
+	})
+
+	now := time.Now()
+	cases := []struct {
+		name   string
+		modify func(c *orderClaims)
+		want   int
+		reason string
+	}{
+		{"valid", func(c *orderClaims) {}, 200, ""},
+		{"expired within skew", func(c *orderClaims) { c.ExpiresAt = mows.NewNumericDate(now.Add(-10 * time.Second)) }, 200, ""},
+		{"expired", func(c *orderClaims) { c.ExpiresAt = mows.NewNumericDate(now.Add(-time.Minute)) }, 401, "expired"},
+		{"not yet valid", func(c *orderClaims) { c.NotBefore = mows.NewNumericDate(now.Add(time.Minute)) }, 401, "not valid yet"},
+		{"nbf within skew", func(c *orderClaims) { c.NotBefore = mows.NewNumericDate(now.Add(10 * time.Second)) }, 200, ""},
+		{"issued in future", func(c *orderClaims) { c.IssuedAt = mows.NewNumericDate(now.Add(time.Hour)) }, 401, "issued in the future"},
+		{"wrong issuer", func(c *orderClaims) { c.Issuer = "evil" }, 401, "issuer"},
+		{"wrong audience", func(c *orderClaims) { c.Audience = mows.Audience{"billing", "users"} }, 401, "audience"},
+		{"one of audiences", func(c *orderClaims) { c.Audience = mows.Audience{"billing", "orders"} }, 200, ""},
+	}
+	for _, tc := range cases {
+		claims := validClaims()
+		tc.modify(&claims)
+		token, _ := signer.Sign(claims)
+
+		w := sendToken(app, token)
+		if w.Code != tc.want {
+			t.Fatalf("%s: expected %d got %d %s", tc.name, tc.want, w.Code, w.Body.String())
+		}
+		if tc.reason != "" && !strings.Contains(w.Header().Get("WWW-Authenticate"), tc.reason) {
+			t.Fatalf("%s: expected challenge to mention %q, got %q", tc.name, tc.reason, w.Header().Get("WWW-Authenticate"))
+		}
+	}
+
+	if w := sendToken(app, ""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected missing token to be rejected, got %d", w.Code)
+	}
+	if w := sendToken(app, "not.a.token"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected malformed token to be rejected, got %d", w.Code)
+	}
+}
+
+func jwksDocument(t *testing.T, signers ...*mows.JWTSigner) []byte {
+	t.Helper()
+
+	var doc struct {
+		Keys []mows.JSONWebKey `json:"keys"`
+	}
+	for _, s := range signers {
+		jwk, ok := s.JSONWebKey()
+		if !ok {
+			t.Fatal("expected public JWK")
+		}
+		doc.Keys = append(doc.Keys, jwk)
+	}
+	data, _ := json.Marshal(doc)
+	return data
+}
+
+func TestJWTJWKSFile(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	rsaSigner, _ := mows.NewJWTSigner(mows.RS256, "rsa-1", rsaKey)
+	ecSigner, _ := mows.NewJWTSigner(mows.ES256, "ec-1", ecKey)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	os.WriteFile(path, jwksDocument(t, rsaSigner, ecSigner), 0600)
+
+	keys := mows.NewJWKSKeySet(mows.JWKSConfig{Source: path})
+	if err := keys.Refresh(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	app := jwtApp(mows.JWTConfig{Keys: keys})
+
+	for _, signer := range []*mows.JWTSigner{rsaSigner, ecSigner} {
+		token, _ := signer.Sign(validClaims())
+		if w := sendToken(app, token); w.Code != 200 {
+			t.Fatalf("expected 200 got %d %s", w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestJWTJWKSRotation(t *testing.T) {
+	_, oldKey, _ := ed25519.GenerateKey(rand.Reader)
+	_, newKey, _ := ed25519.GenerateKey(rand.Reader)
+	oldSigner, _ := mows.NewJWTSigner(mows.EdDSA, "2025", oldKey)
+	newSigner, _ := mows.NewJWTSigner(mows.EdDSA, "2026", newKey)
+
+	var doc atomic.Value
+	doc.Store(jwksDocument(t, oldSigner))
+	var fetches atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		w.Write(doc.Load().([]byte))
+	}))
+	defer srv.Close()
+
+	keys := mows.NewJWKSKeySet(mows.JWKSConfig{
+		Source:             srv.URL,
+		MinRefreshInterval: time.Millisecond,
+	})
+	app := jwtApp(mows.JWTConfig{Keys: keys})
+
+	oldToken, _ := oldSigner.Sign(validClaims())
+	for range 3 {
+		if w := sendToken(app, oldToken); w.Code != 200 {
+			t.Fatalf("expected 200 got %d", w.Code)
+		}
+	}
+	if fetches.Load() != 1 {
+		t.Fatalf("expected keys to be cached, got %d fetches", fetches.Load())
+	}
+
+	// the issuer rotates its key; the unknown kid triggers a reload
+	doc.Store(jwksDocument(t, newSigner))
+	time.Sleep(5 * time.Millisecond)
+
+	newToken, _ := newSigner.Sign(validClaims())
+	if w := sendToken(app, newToken); w.Code != 200 {
+		t.Fatalf("expected rotated key to be fetched, got %d", w.Code)
+	}
+	if fetches.Load() != 2 {
+		t.Fatalf("expected one reload, got %d fetches", fetches.Load())
+	}
+}
+
+func TestJWTPrincipal(t *testing.T) {
+	secret := []byte("secret")
+	signer, _ := mows.NewJWTSigner(mows.HS256, "", secret)
+
+	app := mows.New()
+	app.Use(mows.JWT(mows.JWTConfig{
+		Keys: mows.StaticKeySet{"": secret},
+		Principal: func(c *mows.Context, token *mows.Token) (any, error) {
+			return &authUser{Name: token.Claims.Subject}, nil
+		},
+	}))
+	app.GET("/me", func(c *mows.Context) error {
+		return c.Text(200, c.Principal().(*authUser).Name)
+	})
+
+	token, _ := signer.Sign(validClaims())
+	if w := sendToken(app, token); w.Body.String() != "alice" {
+		t.Fatalf("expected principal alice got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestJWTJWKSFetchDoesNotBlock(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	signer, _ := mows.NewJWTSigner(mows.EdDSA, "k1", key)
+	data := jwksDocument(t, signer)
+
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// every load after the first hangs until released
+		if fetches.Add(1) > 1 {
+			<-release
+		}
+		w.Write(data)
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	keys := mows.NewJWKSKeySet(mows.JWKSConfig{
+		Source:             srv.URL,
+		RefreshInterval:    time.Millisecond,
+		MinRefreshInterval: time.Millisecond,
+	})
+
+	// a client that disconnects while waiting does not cancel the load
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	if _, err := keys.Key(ctx, "k1"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected cancelled wait, got %v", err)
+	}
+	if err := keys.Refresh(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if fetches.Load() != 1 {
+		t.Fatalf("expected the cancelled load to be shared, got %d fetches", fetches.Load())
+	}
+
+	// stale keys are served while the slow reload runs
+	time.Sleep(5 * time.Millisecond)
+	app := jwtApp(mows.JWTConfig{Keys: keys})
+	token, _ := signer.Sign(validClaims())
+
+	done := make(chan int, 10)
+	for range 10 {
+		go func() { done <- sendToken(app, token).Code }()
+	}
+	for range 10 {
+		select {
+		case code := <-done:
+			if code != 200 {
+				t.Fatalf("expected 200 got %d", code)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("verification blocked on the JWKS fetch")
+		}
+	}
+
+	// the reload started in the background and is still hanging
+	for i := 0; i < 100 && fetches.Load() < 2; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if fetches.Load() != 2 {
+		t.Fatalf("expected one shared reload, got %d fetches", fetches.Load())
+	}
+}
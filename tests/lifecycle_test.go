@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/saintmili/mows"
+)
+
+func TestShutdownHooks(t *testing.T) {
+	app := mows.New(mows.WithShutdownConfig(mows.ShutdownConfig{DisableSignals: true}))
+	app.GET("/readyz", app.Readiness())
+
+	order := ""
+	app.OnStart(func(ctx context.Context) error {
+		order += "S"
+		return nil
+	})
+	app.OnShutdown(func(ctx context.Context) error {
+		order += "A"
+		return nil
+	})
+	app.OnShutdown(func(ctx context.Context) error {
+		order += "B"
+		return errors.New("flush failed")
+	})
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- app.RunListener(l) }()
+
+	res, err := http.Get("http://" + l.Addr().String() + "/readyz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != 200 {
+		t.Fatalf("expected ready, got %d", res.StatusCode)
+	}
+
+	err = app.Shutdown(context.Background())
+	if err == nil || err.Error() != "shutdown hook 1: flush failed" {
+		t.Fatalf("unexpected shutdown error: %v", err)
+	}
+	if runErr := <-done; runErr == nil {
+		t.Fatal("expected Run to return the shutdown error")
+	}
+	if order != "SAB" {
+		t.Fatalf("wrong hook order: %s", order)
+	}
+
+	req := httptest.NewRequest("GET", "/readyz", nil)
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if w.Code != 503 {
+		t.Fatalf("expected draining 503 got %d", w.Code)
+	}
+}
+
+func TestStartHookFailure(t *testing.T) {
+	app := mows.New(mows.WithShutdownConfig(mows.ShutdownConfig{DisableSignals: true}))
+	app.OnStart(func(ctx context.Context) error {
+		return errors.New("db unreachable")
+	})
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.RunListener(l); err == nil {
+		t.Fatal("expected start hook error")
+	}
+}
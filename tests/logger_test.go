@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/saintmili/mows"
+)
+
+func TestLoggerLogsErrors(t *testing.T) {
+	var out bytes.Buffer
+	app := mows.New()
+	app.Use(mows.LoggerWithConfig(mows.LoggerConfig{Output: &out}))
+
+	app.POST("/users/:id", func(c *mows.Context) error {
+		var body map[string]string
+		if err := c.BindJSON(&body); err != nil {
+			return err
+		}
+		return mows.NewHTTPError(404, "user not found")
+	})
+
+	req := httptest.NewRequest("POST", "/users/42", strings.NewReader(`{"name":"mows"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "curl/8.0")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	if w.Code != 404 {
+		t.Fatalf("expected 404 got %d", w.Code)
+	}
+
+	line := out.String()
+	for _, want := range []string{"] 404 |", "POST /users/42 (/users/:id)", "in=15B", `"curl/8.0"`, "error: user not found"} {
+		if !strings.Contains(line, want) {
+			t.Fatalf("log line %q does not contain %q", line, want)
+		}
+	}
+}
+
+func TestLoggerSampling(t *testing.T) {
+	var out bytes.Buffer
+	app := mows.New()
+	app.Use(mows.LoggerWithConfig(mows.LoggerConfig{
+		Output: &out,
+		Sample: map[string]float64{"/metrics": 0},
+	}))
+
+	app.GET("/metrics", func(c *mows.Context) error {
+		return c.Text(200, "ok")
+	})
+	app.GET("/fail", func(c *mows.Context) error {
+		return errors.New("bad")
+	})
+
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/metrics", nil))
+	if out.Len() != 0 {
+		t.Fatalf("sampled route should not be logged: %s", out.String())
+	}
+
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/fail", nil))
+	if !strings.Contains(out.String(), "error: bad") {
+		t.Fatalf("expected error to be logged: %s", out.String())
+	}
+}
@@ -0,0 +1,237 @@
+package tests
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/saintmili/mows"
+)
+
+type address struct {
+	City string `json:"city" validate:"required"`
+}
+
+type account struct {
+	ID        string    `json:"id"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+	Address   *address  `json:"address,omitempty"`
+	Friends   []account `json:"friends,omitempty"`
+}
+
+type createAccountRequest struct {
+	Name    string   `json:"name" validate:"required,min=3,max=40"`
+	Email   string   `json:"email" validate:"required,email"`
+	Age     int      `json:"age" validate:"gte=18,lte=130"`
+	Plan    string   `json:"plan" validate:"oneof=free pro"`
+	Tags    []string `json:"tags" validate:"max=5,dive,min=2"`
+	Address address  `json:"address"`
+	Secret  string   `json:"-"`
+}
+
+type updateAccountRequest struct {
+	ID      string `uri:"id"`
+	Version int    `header:"If-Match" validate:"required"`
+	Notify  bool   `query:"notify"`
+	Name    string `json:"name" validate:"required"`
+}
+
+type listAccountsRequest struct {
+	Page  int    `query:"page" validate:"min=1"`
+	Order string `query:"order" validate:"oneof=asc desc"`
+}
+
+func openAPIApp() *mows.Engine {
+	app := mows.New()
+	app.ServeOpenAPI(mows.OpenAPIConfig{Title: "Accounts", Version: "2.0.0"})
+
+	noop := func(c *mows.Context) error { return nil }
+	api := app.Group("/api")
+	api.POST("/accounts", noop).
+		Summary("Create an account").
+		Tags("accounts").
+		OperationID("createAccount").
+		Request(createAccountRequest{}).
+		Response(http.StatusCreated, account{})
+	api.GET("/accounts", noop).
+		Tags("accounts").
+		Request(listAccountsRequest{}).
+		Response(http.StatusOK, []account{})
+	api.PUT("/accounts/:id", noop).
+		Request(updateAccountRequest{}).
+		Param("path", "id", "Account ID").
+		Response(http.StatusOK, account{}).
+		Deprecated()
+	api.DELETE("/accounts/:id", noop).
+		Response(http.StatusNoContent, nil)
+	app.GET("/internal", noop).Hidden()
+	app.Static("/assets", ".")
+	return app
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	doc := openAPIApp().OpenAPI()
+
+	if doc.OpenAPI != "3.1.0" || doc.Info.Title != "Accounts" || doc.Info.Version != "2.0.0" {
+		t.Fatalf("unexpected header %+v %+v", doc.OpenAPI, doc.Info)
+	}
+	for _, p := range []string{"/openapi.json", "/internal", "/assets/*filepath"} {
+		if _, ok := doc.Paths[p]; ok {
+			t.Fatalf("expected %s to be excluded", p)
+		}
+	}
+
+	create := doc.Paths["/api/accounts"]["post"]
+	if create == nil || create.Summary != "Create an account" || create.OperationID != "createAccount" || !slices.Equal(create.Tags, []string{"accounts"}) {
+		t.Fatalf("unexpected create operation %+v", create)
+	}
+	if create.Responses["201"].Content["application/json"].Schema.Ref != "#/components/schemas/account" {
+		t.Fatalf("expected account response, got %+v", create.Responses["201"])
+	}
+
+	body := doc.Components.Schemas[create.RequestBody.Content["application/json"].Schema.Ref[len("#/components/schemas/"):]]
+	if body == nil || !slices.Equal(body.Required, []string{"name", "email"}) {
+		t.Fatalf("unexpected request schema %+v", body)
+	}
+	if _, ok := body.Properties["Secret"]; ok {
+		t.Fatal(`expected json:"-" fields to be skipped`)
+	}
+	name := body.Properties["name"]
+	if *name.MinLength != 3 || *name.MaxLength != 40 {
+		t.Fatalf("unexpected name constraints %+v", name)
+	}
+	if body.Properties["email"].Format != "email" {
+		t.Fatal("expected email format")
+	}
+	age := body.Properties["age"]
+	if age.Type != "integer" || *age.Minimum != 18 || *age.Maximum != 130 {
+		t.Fatalf("unexpected age constraints %+v", age)
+	}
+	if !slices.Equal(body.Properties["plan"].Enum, []any{"free", "pro"}) {
+		t.Fatalf("unexpected plan enum %+v", body.Properties["plan"].Enum)
+	}
+	tags := body.Properties["tags"]
+	if *tags.MaxItems != 5 || *tags.Items.MinLength != 2 {
+		t.Fatalf("unexpected tags constraints %+v %+v", tags, tags.Items)
+	}
+
+	acct := doc.Components.Schemas["account"]
+	if acct.Properties["created_at"].Format != "date-time" || acct.Properties["friends"].Items.Ref != "#/components/schemas/account" {
+		t.Fatalf("unexpected account schema %+v", acct)
+	}
+	if !slices.Equal(doc.Components.Schemas["address"].Required, []string{"city"}) {
+		t.Fatal("expected nested struct component")
+	}
+
+	list := doc.Paths["/api/accounts"]["get"]
+	if list.RequestBody != nil || len(list.Parameters) != 2 {
+		t.Fatalf("expected query parameters only, got %+v", list)
+	}
+	if p := list.Parameters[0]; p.Name != "page" || p.In != "query" || p.Required || *p.Schema.Minimum != 1 {
+		t.Fatalf("unexpected page parameter %+v", p)
+	}
+	if list.Responses["200"].Content["application/json"].Schema.Items.Ref != "#/components/schemas/account" {
+		t.Fatal("expected array of accounts")
+	}
+
+	update := doc.Paths["/api/accounts/{id}"]["put"]
+	if !update.Deprecated || len(update.Parameters) != 3 {
+		t.Fatalf("unexpected update operation %+v", update)
+	}
+	for _, p := range update.Parameters {
+		switch p.Name {
+		case "id":
+			if p.In != "path" || !p.Required || p.Description != "Account ID" {
+				t.Fatalf("unexpected id parameter %+v", p)
+			}
+		case "If-Match":
+			if p.In != "header" || !p.Required || p.Schema.Type != "integer" {
+				t.Fatalf("unexpected header parameter %+v", p)
+			}
+		case "notify":
+			if p.In != "query" || p.Schema.Type != "boolean" {
+				t.Fatalf("unexpected query parameter %+v", p)
+			}
+		}
+	}
+	updateBody := update.RequestBody.Content["application/json"].Schema
+	if len(updateBody.Properties) != 1 || !slices.Equal(updateBody.Required, []string{"name"}) {
+		t.Fatalf("expected body of json fields only, got %+v", updateBody)
+	}
+
+	remove := doc.Paths["/api/accounts/{id}"]["delete"]
+	if len(remove.Parameters) != 1 || remove.Parameters[0].Name != "id" || remove.Responses["204"].Content != nil {
+		t.Fatalf("unexpected delete operation %+v", remove)
+	}
+}
+
+func TestOpenAPIServed(t *testing.T) {
+	app := openAPIApp()
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/openapi.json", nil))
+	if w.Code != 200 {
+		t.Fatalf("expected 200 got %d", w.Code)
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc["openapi"] != "3.1.0" {
+		t.Fatalf("unexpected document %s", w.Body.String())
+	}
+	paths := doc["paths"].(map[string]any)
+	if _, ok := paths["/api/accounts/{id}"]; !ok {
+		t.Fatalf("expected templated path, got %v", paths)
+	}
+}
+
+func TestOpenAPISchemaNameCollisions(t *testing.T) {
+	// three distinct types named item in the same package
+	newItem := func() any {
+		type item struct {
+			A string `json:"a"`
+		}
+		return item{}
+	}
+	oldItem := func() any {
+		type item struct {
+			B int `json:"b"`
+		}
+		return item{}
+	}
+	type item struct {
+		C bool `json:"c"`
+	}
+
+	app := mows.New()
+	app.GET("/a", func(c *mows.Context) error { return nil }).Response(200, newItem())
+	app.GET("/b", func(c *mows.Context) error { return nil }).Response(200, oldItem())
+	app.GET("/c", func(c *mows.Context) error { return nil }).Response(200, item{})
+	app.Static("/assets", t.TempDir())
+	doc := app.OpenAPI()
+
+	seen := map[string]string{}
+	for path, field := range map[string]string{"/a": "a", "/b": "b", "/c": "c"} {
+		ref := doc.Paths[path]["get"].Responses["200"].Content["application/json"].Schema.Ref
+		if other, ok := seen[ref]; ok {
+			t.Fatalf("%s and %s share %s", path, other, ref)
+		}
+		seen[ref] = path
+
+		name := ref[len("#/components/schemas/"):]
+		if _, ok := doc.Components.Schemas[name].Properties[field]; !ok {
+			t.Fatalf("%s: %s does not describe its type: %+v", path, ref, doc.Components.Schemas[name])
+		}
+	}
+
+	if len(doc.Paths) != 3 {
+		t.Fatalf("expected the Static route to be excluded, got %v", slices.Collect(maps.Keys(doc.Paths)))
+	}
+}
@@ -0,0 +1,142 @@
+package tests
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/saintmili/mows"
+)
+
+func proxyApp(t *testing.T) *mows.Engine {
+	t.Helper()
+
+	app := mows.New()
+	if err := app.SetTrustedProxies([]string{"10.0.0.0/8", "fd00::/8"}); err != nil {
+		t.Fatal(err)
+	}
+
+	app.GET("/ip", func(c *mows.Context) error {
+		return c.Text(200, c.ClientIP()+" "+c.Scheme()+" "+c.Host())
+	})
+	return app
+}
+
+func TestClientIPTrustedProxy(t *testing.T) {
+	app := proxyApp(t)
+
+	req := httptest.NewRequest("GET", "/ip", nil)
+	req.RemoteAddr = "10.0.0.5:1234"
+	// the client spoofed 1.1.1.1; our proxies appended the real hops
+	req.Header.Set("X-Forwarded-For", "1.1.1.1, 203.0.113.7, 10.0.0.9")
+	req.Header.Set("X-Forwarded-Proto", "https")
+	req.Header.Set("X-Forwarded-Host", "api.example.com")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	if w.Body.String() != "203.0.113.7 https api.example.com" {
+		t.Fatalf("unexpected result: %s", w.Body.String())
+	}
+}
+
+func TestClientIPForwardedHeader(t *testing.T) {
+	app := proxyApp(t)
+	app.SetProxyHeader(mows.ProxyForwarded)
+
+	req := httptest.NewRequest("GET", "/ip", nil)
+	req.RemoteAddr = "[fd00::1]:443"
+	req.Header.Set("Forwarded", `for="[2001:db8:cafe::17]:4711";proto=https;host=shop.example.com`)
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	if w.Body.String() != "2001:db8:cafe::17 https shop.example.com" {
+		t.Fatalf("unexpected result: %s", w.Body.String())
+	}
+}
+
+func TestClientIPUntrustedPeer(t *testing.T) {
+	app := proxyApp(t)
+	app.SetTrustedPlatform(mows.PlatformCloudflare)
+
+	req := httptest.NewRequest("GET", "/ip", nil)
+	req.RemoteAddr = "198.51.100.1:5555"
+	req.Header.Set("X-Forwarded-For", "1.1.1.1")
+	req.Header.Set("CF-Connecting-IP", "2.2.2.2")
+	req.Header.Set("X-Forwarded-Proto", "https")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	if w.Body.String() != "198.51.100.1 http example.com" {
+		t.Fatalf("headers from untrusted peers must be ignored: %s", w.Body.String())
+	}
+}
+
+func TestSchemeAndHostIgnoreSpoofedEntries(t *testing.T) {
+	app := proxyApp(t)
+
+	// the client sent https and evil.com; the edge proxy appended its own
+	req := httptest.NewRequest("GET", "/ip", nil)
+	req.RemoteAddr = "10.0.0.5:1234"
+	req.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.9")
+	req.Header.Set("X-Forwarded-Proto", "https, http, http")
+	req.Header.Set("X-Forwarded-Host", "evil.com, api.example.com, internal")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	if w.Body.String() != "203.0.113.7 http api.example.com" {
+		t.Fatalf("unexpected result: %s", w.Body.String())
+	}
+
+	app.SetProxyHeader(mows.ProxyForwarded)
+	req = httptest.NewRequest("GET", "/ip", nil)
+	req.RemoteAddr = "10.0.0.5:1234"
+	req.Header.Set("Forwarded", `for=198.51.100.1;proto=https;host=evil.com, for=203.0.113.7;proto=http;host=api.example.com`)
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	if w.Body.String() != "203.0.113.7 http api.example.com" {
+		t.Fatalf("unexpected result: %s", w.Body.String())
+	}
+}
+
+func TestClientIPIgnoresUnselectedHeaders(t *testing.T) {
+	app := proxyApp(t)
+
+	// the proxy only sets X-Forwarded-*; the client injected the rest
+	req := httptest.NewRequest("GET", "/ip", nil)
+	req.RemoteAddr = "10.0.0.5:1234"
+	req.Header.Set("Forwarded", "for=192.168.1.1;proto=https;host=evil.com")
+	req.Header.Set("X-Real-IP", "192.168.1.2")
+	req.Header.Set("X-Forwarded-For", "203.0.113.7")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	if w.Body.String() != "203.0.113.7 http example.com" {
+		t.Fatalf("unexpected result: %s", w.Body.String())
+	}
+
+	// without X-Forwarded-For there is no fallback to the other headers
+	req.Header.Del("X-Forwarded-For")
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	if w.Body.String() != "10.0.0.5 http example.com" {
+		t.Fatalf("unexpected result: %s", w.Body.String())
+	}
+}
+
+func TestClientIPXRealIP(t *testing.T) {
+	app := proxyApp(t)
+	app.SetProxyHeader(mows.ProxyXRealIP)
+
+	req := httptest.NewRequest("GET", "/ip", nil)
+	req.RemoteAddr = "10.0.0.5:1234"
+	req.Header.Set("X-Real-IP", "203.0.113.7")
+	req.Header.Set("X-Forwarded-For", "192.168.1.1")
+	req.Header.Set("X-Forwarded-Proto", "https")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	if w.Body.String() != "203.0.113.7 http example.com" {
+		t.Fatalf("unexpected result: %s", w.Body.String())
+	}
+}
@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/saintmili/mows"
+)
+
+func TestRateLimitTokenBucket(t *testing.T) {
+	app := mows.New()
+	app.Use(mows.RateLimit(mows.RateLimitConfig{Limit: 2, Window: time.Minute}))
+
+	app.GET("/ping", func(c *mows.Context) error {
+		return c.Text(200, "pong")
+	})
+
+	codes := []int{}
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest("GET", "/ping", nil)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		codes = append(codes, w.Code)
+
+		if i == 2 && w.Header().Get("Retry-After") == "" {
+			t.Fatal("expected Retry-After header")
+		}
+	}
+
+	if codes[0] != 200 || codes[1] != 200 || codes[2] != 429 {
+		t.Fatalf("unexpected status codes: %v", codes)
+	}
+}
+
+func TestRateLimitSlidingWindowByHeader(t *testing.T) {
+	app := mows.New()
+
+	api := app.Group("/api")
+	api.Use(mows.RateLimit(mows.RateLimitConfig{
+		Algorithm: mows.SlidingWindow,
+		Limit:     1,
+		KeyFunc:   mows.KeyByHeader("X-API-Key"),
+	}))
+
+	api.GET("/items", func(c *mows.Context) error {
+		return c.Text(200, "ok")
+	})
+
+	send := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/items", nil)
+		req.Header.Set("X-API-Key", key)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := send("a"); w.Code != 200 || w.Header().Get("RateLimit-Remaining") != "0" {
+		t.Fatalf("unexpected first response: %d %q", w.Code, w.Header().Get("RateLimit-Remaining"))
+	}
+	if w := send("a"); w.Code != 429 {
+		t.Fatalf("expected 429 got %d", w.Code)
+	}
+	if w := send("b"); w.Code != 200 {
+		t.Fatalf("expected separate key to pass, got %d", w.Code)
+	}
+}
+
+func TestRateLimitHeadersAgree(t *testing.T) {
+	app := mows.New()
+	app.Use(mows.RateLimit(mows.RateLimitConfig{Limit: 2, Burst: 5, Window: time.Minute}))
+	app.GET("/ping", func(c *mows.Context) error {
+		return c.Text(200, "pong")
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/ping", nil))
+	if w.Header().Get("RateLimit-Limit") != "5" || w.Header().Get("RateLimit-Policy") != "5;w=60" {
+		t.Fatalf("unexpected headers: limit %q policy %q", w.Header().Get("RateLimit-Limit"), w.Header().Get("RateLimit-Policy"))
+	}
+}
+
+func TestRateLimitKeyByHeaderFallsBackToIP(t *testing.T) {
+	app := mows.New()
+	app.Use(mows.RateLimit(mows.RateLimitConfig{
+		Limit:   1,
+		KeyFunc: mows.KeyByHeader("X-API-Key"),
+	}))
+	app.GET("/ping", func(c *mows.Context) error {
+		return c.Text(200, "pong")
+	})
+
+	send := func(remoteAddr string) int {
+		req := httptest.NewRequest("GET", "/ping", nil)
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := send("192.0.2.1:1234"); code != 200 {
+		t.Fatalf("expected 200 got %d", code)
+	}
+	if code := send("192.0.2.2:1234"); code != 200 {
+		t.Fatalf("expected requests without the header to be keyed by IP, got %d", code)
+	}
+	if code := send("192.0.2.1:1234"); code != 429 {
+		t.Fatalf("expected 429 got %d", code)
+	}
+}
@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/saintmili/mows"
+)
+
+func TestRecoverUsesErrorHandler(t *testing.T) {
+	app := mows.New()
+	app.Use(mows.Recover())
+
+	var got *mows.PanicError
+	app.SetErrorHandler(func(c *mows.Context, err error) {
+		errors.As(err, &got)
+		c.Text(500, "handled")
+	})
+
+	app.GET("/panic", func(c *mows.Context) error {
+		panic("boom")
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/panic", nil))
+
+	if w.Code != 500 || w.Body.String() != "handled" {
+		t.Fatalf("expected custom error handler, got %d %s", w.Code, w.Body.String())
+	}
+	if got == nil || got.Value != "boom" || len(got.Stack) == 0 {
+		t.Fatalf("expected PanicError with value and stack, got %+v", got)
+	}
+	if !strings.HasSuffix(got.File, "recover_test.go") {
+		t.Fatalf("expected panic origin in test file, got %s:%d", got.File, got.Line)
+	}
+}
+
+func TestRecoverHidesDetailsOutsideDevMode(t *testing.T) {
+	reported := false
+	app := mows.New()
+	app.Use(mows.RecoverWithConfig(mows.RecoverConfig{
+		OnPanic: func(c *mows.Context, err *mows.PanicError) {
+			reported = true
+		},
+	}))
+
+	app.GET("/panic", func(c *mows.Context) error {
+		panic("secret")
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/panic", nil))
+
+	if w.Code != 500 || strings.Contains(w.Body.String(), "secret") {
+		t.Fatalf("unexpected response: %d %s", w.Code, w.Body.String())
+	}
+	if !reported {
+		t.Fatal("OnPanic hook was not called")
+	}
+}
+
+func TestRecoverDevModePage(t *testing.T) {
+	app := mows.New()
+	app.DevMode(true)
+	app.Use(mows.Recover())
+
+	app.GET("/panic", func(c *mows.Context) error {
+		panic("boom")
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/panic", nil))
+
+	var page struct {
+		Error  string `json:"error"`
+		Stack  string `json:"stack"`
+		Source []struct {
+			Current bool `json:"current"`
+		} `json:"source"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+		t.Fatal(err)
+	}
+	if page.Error != "panic: boom" || page.Stack == "" || len(page.Source) == 0 {
+		t.Fatalf("unexpected dev page: %s", w.Body.String())
+	}
+
+	req := httptest.NewRequest("GET", "/panic", nil)
+	req.Header.Set("Accept", "text/html")
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if !strings.Contains(w.Body.String(), "<h1>panic: boom</h1>") {
+		t.Fatalf("expected HTML dev page, got %s", w.Body.String())
+	}
+}
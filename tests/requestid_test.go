@@ -0,0 +1,67 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/saintmili/mows"
+)
+
+var uuidv7 = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestRequestIDGenerated(t *testing.T) {
+	app := mows.New()
+	app.Use(mows.RequestID())
+
+	var fromCtx string
+	app.GET("/fail", func(c *mows.Context) error {
+		fromCtx = mows.RequestIDFromContext(c.Request.Context())
+		return errors.New("boom")
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/fail", nil))
+
+	id := w.Header().Get("X-Request-ID")
+	if !uuidv7.MatchString(id) {
+		t.Fatalf("expected UUIDv7 got %q", id)
+	}
+	if fromCtx != id {
+		t.Fatalf("request context ID %q does not match %q", fromCtx, id)
+	}
+
+	var body struct {
+		RequestID string `json:"request_id"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if body.RequestID != id {
+		t.Fatalf("error response should include request ID, got %s", w.Body.String())
+	}
+}
+
+func TestRequestIDPropagated(t *testing.T) {
+	app := mows.New()
+	app.Use(mows.RequestID())
+
+	app.GET("/ping", func(c *mows.Context) error {
+		return c.Text(200, c.RequestID())
+	})
+
+	send := func(id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/ping", nil)
+		req.Header.Set("X-Request-ID", id)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := send("abc-123"); w.Body.String() != "abc-123" {
+		t.Fatalf("expected incoming ID to be reused, got %s", w.Body.String())
+	}
+	if w := send("bad id\n<script>"); w.Body.String() == "bad id\n<script>" || !uuidv7.MatchString(w.Body.String()) {
+		t.Fatalf("invalid incoming ID should be replaced, got %q", w.Body.String())
+	}
+}
@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/saintmili/mows"
+)
+
+func listUsers(c *mows.Context) error {
+	return c.JSON(200, []string{})
+}
+
+func audit(c *mows.Context) error {
+	return nil
+}
+
+type adminStats struct {
+	Users int `json:"users"`
+}
+
+func getStats(c *mows.Context, req struct{}) (adminStats, error) {
+	return adminStats{Users: 1}, nil
+}
+
+func routesApp() *mows.Engine {
+	app := mows.New()
+	app.Use(mows.Logger())
+	app.GET("/health", func(c *mows.Context) error {
+		return c.Text(200, "ok")
+	})
+
+	admin := app.Group("/admin", mows.JWT(mows.JWTConfig{Keys: mows.StaticKeySet{"": []byte("k")}}))
+	admin.GET("/users", audit, listUsers).Summary("List users")
+	admin.GET("/stats", mows.Typed(getStats))
+	admin.GET("/routes", app.RoutesHandler()).Hidden()
+	return app
+}
+
+func TestRoutes(t *testing.T) {
+	routes := routesApp().Routes()
+
+	var users *mows.RouteInfo
+	for i, r := range routes {
+		if r.Pattern == "/admin/users" {
+			users = &routes[i]
+		}
+	}
+	if users == nil {
+		t.Fatalf("expected /admin/users in %+v", routes)
+	}
+	if users.Method != "GET" || users.Handler != "tests.listUsers" || users.Group != "/admin" || users.Meta.Summary != "List users" {
+		t.Fatalf("unexpected route %+v", users)
+	}
+	if !slices.Equal(users.Middlewares, []string{"mows.Logger", "mows.JWT", "tests.audit"}) {
+		t.Fatalf("unexpected middlewares %v", users.Middlewares)
+	}
+
+	for _, r := range routes {
+		switch r.Pattern {
+		case "/health":
+			if r.Group != "" || !slices.Equal(r.Middlewares, []string{"mows.Logger"}) {
+				t.Fatalf("unexpected root route %+v", r)
+			}
+		case "/admin/stats":
+			if r.Handler != "tests.getStats" {
+				t.Fatalf("expected typed handler to report the adapted function, got %q", r.Handler)
+			}
+		}
+	}
+
+	// no unauthenticated routes under /admin
+	for _, r := range routes {
+		if strings.HasPrefix(r.Pattern, "/admin") && !slices.Contains(r.Middlewares, "mows.JWT") {
+			t.Fatalf("%s %s is not authenticated", r.Method, r.Pattern)
+		}
+	}
+}
+
+func TestRoutesHandler(t *testing.T) {
+	app := mows.New()
+	app.GET("/users", listUsers)
+	app.GET("/debug/routes", app.RoutesHandler())
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/debug/routes", nil))
+	if w.Code != 200 || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain") {
+		t.Fatalf("expected text table, got %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if len(lines) != 3 || strings.Fields(lines[0])[0] != "METHOD" {
+		t.Fatalf("unexpected table %q", w.Body.String())
+	}
+	if got := strings.Fields(lines[2]); !slices.Equal(got, []string{"GET", "/users", "tests.listUsers", "-"}) {
+		t.Fatalf("unexpected row %v", got)
+	}
+}
+
+func TestRouteGuardsMatchRoutes(t *testing.T) {
+	rbac := mows.NewRBAC()
+	app := mows.New()
+	app.Use(mows.Logger())
+	admin := app.Group("/admin", mows.JWT(mows.JWTConfig{Keys: mows.StaticKeySet{"": []byte("k")}}), mows.Authorize(rbac)).Require("admin")
+	// audit is an extra per-route handler, run as middleware
+	admin.GET("/users", audit, listUsers)
+	admin.GET("/stats", listUsers)
+
+	routes := app.Routes()
+	guards := app.RouteGuards()
+	if len(routes) != len(guards) {
+		t.Fatalf("expected %d guards got %d", len(routes), len(guards))
+	}
+	for i, r := range routes {
+		g := guards[i]
+		if g.Method != r.Method || g.Pattern != r.Pattern {
+			t.Fatalf("expected guards in route order, got %s %s for %s %s", g.Method, g.Pattern, r.Method, r.Pattern)
+		}
+		if !slices.Equal(g.Guards, []string{"mows.JWT", "mows.Authorize"}) || len(g.Policies) != 1 {
+			t.Fatalf("unexpected guards for %s: %+v", r.Pattern, g)
+		}
+	}
+
+	if !slices.Equal(routes[1].Middlewares, []string{"mows.Logger", "mows.JWT", "mows.Authorize", "tests.audit"}) {
+		t.Fatalf("unexpected middlewares %v", routes[1].Middlewares)
+	}
+}
@@ -0,0 +1,148 @@
+package tests
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/saintmili/mows"
+)
+
+func TestSecureHeaders(t *testing.T) {
+	app := mows.New()
+	app.Use(mows.Secure(mows.DefaultSecureConfig()))
+	app.GET("/", func(c *mows.Context) error {
+		return c.Text(200, "ok")
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "http://example.com/", nil))
+
+	want := map[string]string{
+		"X-Content-Type-Options":       "nosniff",
+		"X-Frame-Options":              "DENY",
+		"Referrer-Policy":              "strict-origin-when-cross-origin",
+		"Cross-Origin-Opener-Policy":   "same-origin",
+		"Cross-Origin-Resource-Policy": "same-origin",
+	}
+	for name, value := range want {
+		if got := w.Header().Get(name); got != value {
+			t.Fatalf("%s: expected %q got %q", name, value, got)
+		}
+	}
+	if w.Header().Get("Cross-Origin-Embedder-Policy") != "" {
+		t.Fatal("expected empty fields to be omitted")
+	}
+	if w.Header().Get("Strict-Transport-Security") != "" {
+		t.Fatal("expected no HSTS over plain http")
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "https://example.com/", nil))
+	if got := w.Header().Get("Strict-Transport-Security"); got != "max-age=31536000; includeSubDomains" {
+		t.Fatalf("unexpected HSTS %q", got)
+	}
+}
+
+func TestSecureCSPNonce(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "page.html"), []byte(`<script nonce="{{ cspNonce }}"></script>`), 0600)
+
+	app := mows.New()
+	if err := app.LoadTemplates(filepath.Join(dir, "*.html")); err != nil {
+		t.Fatal(err)
+	}
+	app.Use(mows.Secure(mows.SecureConfig{
+		CSP: mows.NewCSP().
+			DefaultSrc("'self'").
+			ScriptSrc("'self'", mows.CSPNonce).
+			FrameAncestors("'none'"),
+	}))
+	app.GET("/", func(c *mows.Context) error {
+		return c.HTML(200, "page.html", nil)
+	})
+
+	nonce := regexp.MustCompile(`nonce="([^"]+)"`)
+	var seen []string
+	for range 2 {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+		match := nonce.FindStringSubmatch(w.Body.String())
+		if match == nil {
+			t.Fatalf("expected nonce in %s", w.Body.String())
+		}
+		policy := w.Header().Get("Content-Security-Policy")
+		expected := "default-src 'self'; script-src 'self' 'nonce-" + match[1] + "'; frame-ancestors 'none'"
+		if policy != expected {
+			t.Fatalf("expected policy %q got %q", expected, policy)
+		}
+		seen = append(seen, match[1])
+	}
+	if seen[0] == seen[1] {
+		t.Fatal("expected a fresh nonce per request")
+	}
+}
+
+func TestSecureCSPReportOnly(t *testing.T) {
+	var reports []mows.CSPReport
+
+	config := mows.SecureConfig{
+		CSP:           mows.NewCSP().DefaultSrc("'self'"),
+		CSPReportOnly: true,
+		CSPReportURI:  "/csp-report",
+	}
+	app := mows.New()
+	app.Use(mows.Secure(config))
+	app.GET("/", func(c *mows.Context) error {
+		return c.Text(200, "ok")
+	})
+	app.POST("/csp-report", mows.CSPReportHandler(func(c *mows.Context, r mows.CSPReport) {
+		reports = append(reports, r)
+	}))
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Header().Get("Content-Security-Policy") != "" {
+		t.Fatal("expected no enforced policy in report-only mode")
+	}
+	if got := w.Header().Get("Content-Security-Policy-Report-Only"); got != "default-src 'self'; report-uri /csp-report; report-to csp-endpoint" {
+		t.Fatalf("unexpected report-only policy %q", got)
+	}
+	if got := w.Header().Get("Reporting-Endpoints"); got != `csp-endpoint="/csp-report"` {
+		t.Fatalf("unexpected reporting endpoints %q", got)
+	}
+
+	send := func(contentType, body string) int {
+		req := httptest.NewRequest("POST", "/csp-report", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	legacy := `{"csp-report":{"document-uri":"https://example.com/","blocked-uri":"https://evil.example/x.js","violated-directive":"script-src-elem","disposition":"report"}}`
+	if code := send("application/csp-report", legacy); code != 204 {
+		t.Fatalf("expected 204 got %d", code)
+	}
+	reportTo := `[{"type":"csp-violation","body":{"documentURL":"https://example.com/a","blockedURL":"inline","effectiveDirective":"style-src-attr","lineNumber":3}},{"type":"deprecation","body":{}}]`
+	if code := send("application/reports+json", reportTo); code != 204 {
+		t.Fatalf("expected 204 got %d", code)
+	}
+	if code := send("application/csp-report", "not json"); code != 400 {
+		t.Fatalf("expected 400 got %d", code)
+	}
+
+	if len(reports) != 2 {
+		t.Fatalf("expected 2 reports got %+v", reports)
+	}
+	if reports[0].BlockedURI != "https://evil.example/x.js" || reports[0].EffectiveDirective != "script-src-elem" {
+		t.Fatalf("unexpected legacy report %+v", reports[0])
+	}
+	if reports[1].DocumentURI != "https://example.com/a" || reports[1].EffectiveDirective != "style-src-attr" || reports[1].LineNumber != 3 {
+		t.Fatalf("unexpected reporting api report %+v", reports[1])
+	}
+}
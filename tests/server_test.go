@@ -0,0 +1,235 @@
+package tests
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/saintmili/mows"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/hpack"
+)
+
+func TestServerConfig(t *testing.T) {
+	app := mows.New(mows.WithServerConfig(mows.ServerConfig{
+		ReadHeaderTimeout: 3 * time.Second,
+		MaxHeaderBytes:    4096,
+	}))
+
+	srv := app.Server()
+	if srv.ReadHeaderTimeout != 3*time.Second || srv.MaxHeaderBytes != 4096 {
+		t.Fatalf("server config not applied: %v %d", srv.ReadHeaderTimeout, srv.MaxHeaderBytes)
+	}
+}
+
+func TestRunListener(t *testing.T) {
+	app := mows.New()
+	app.GET("/ping", func(c *mows.Context) error {
+		return c.Text(200, "pong")
+	})
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- app.RunListener(l) }()
+
+	res, err := http.Get("http://" + l.Addr().String() + "/ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+
+	if string(body) != "pong" {
+		t.Fatalf("expected pong got %s", body)
+	}
+
+	app.Server().Close()
+	if err := <-done; !errors.Is(err, http.ErrServerClosed) {
+		t.Fatalf("expected ErrServerClosed got %v", err)
+	}
+}
+
+func TestRunUnix(t *testing.T) {
+	app := mows.New()
+	app.GET("/ping", func(c *mows.Context) error {
+		return c.Text(200, "pong")
+	})
+
+	socket := filepath.Join(t.TempDir(), "mows.sock")
+
+	done := make(chan error, 1)
+	go func() { done <- app.RunUnix(socket, 0600) }()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socket)
+		},
+	}}
+
+	var res *http.Response
+	var err error
+	for i := 0; i < 50; i++ {
+		if res, err = client.Get("http://unix/ping"); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode != 200 {
+		t.Fatalf("expected 200 got %d", res.StatusCode)
+	}
+
+	app.Server().Close()
+	<-done
+}
+
+// h2cServer starts an app with H2C enabled and returns its address.
+func h2cServer(t *testing.T) string {
+	app := mows.New(mows.WithServerConfig(mows.ServerConfig{
+		H2C:   true,
+		HTTP2: &http.HTTP2Config{MaxConcurrentStreams: 50},
+	}))
+	app.GET("/proto", func(c *mows.Context) error {
+		return c.Text(200, c.Protocol())
+	})
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := app.Server()
+	go app.RunListener(l)
+	t.Cleanup(func() { srv.Close() })
+	return l.Addr().String()
+}
+
+func TestH2C(t *testing.T) {
+	addr := h2cServer(t)
+
+	var protocols http.Protocols
+	protocols.SetUnencryptedHTTP2(true)
+	client := &http.Client{Transport: &http.Transport{Protocols: &protocols}}
+
+	res, err := client.Get("http://" + addr + "/proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+
+	if res.ProtoMajor != 2 || string(body) != "h2c" {
+		t.Fatalf("expected h2c over HTTP/2 got %s over %s", body, res.Proto)
+	}
+
+	// HTTP/1.1 clients keep working on the same port
+	res, err = http.Get("http://" + addr + "/proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ = io.ReadAll(res.Body)
+	res.Body.Close()
+	if res.ProtoMajor != 1 || string(body) != "http/1.1" {
+		t.Fatalf("expected http/1.1 got %s over %s", body, res.Proto)
+	}
+}
+
+func TestH2CUpgrade(t *testing.T) {
+	addr := h2cServer(t)
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	// an empty HTTP2-Settings header keeps the default settings
+	io.WriteString(conn, "GET /proto HTTP/1.1\r\nHost: test\r\n"+
+		"Connection: Upgrade, HTTP2-Settings\r\nUpgrade: h2c\r\nHTTP2-Settings: \r\n\r\n")
+
+	br := bufio.NewReader(conn)
+	res, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("expected 101 got %d", res.StatusCode)
+	}
+
+	io.WriteString(conn, http2.ClientPreface)
+	framer := http2.NewFramer(conn, br)
+	if err := framer.WriteSettings(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the response to the upgrade request arrives on stream 1; the
+	// request itself was sent over HTTP/1.1
+	body := readH2CResponse(t, framer, 1)
+	if string(body) != "http/1.1" {
+		t.Fatalf("unexpected upgrade response %q", body)
+	}
+
+	// later requests on the connection use HTTP/2
+	var block bytes.Buffer
+	encoder := hpack.NewEncoder(&block)
+	for _, f := range [][2]string{{":method", "GET"}, {":scheme", "http"}, {":authority", "test"}, {":path", "/proto"}} {
+		encoder.WriteField(hpack.HeaderField{Name: f[0], Value: f[1]})
+	}
+	err = framer.WriteHeaders(http2.HeadersFrameParam{
+		StreamID:      3,
+		BlockFragment: block.Bytes(),
+		EndStream:     true,
+		EndHeaders:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body := readH2CResponse(t, framer, 3); string(body) != "h2c" {
+		t.Fatalf("expected h2c got %q", body)
+	}
+}
+
+// readH2CResponse reads frames until the response body of stream ends.
+// It checks that the server applies the HTTP2 config.
+func readH2CResponse(t *testing.T, framer *http2.Framer, stream uint32) []byte {
+	var body []byte
+	for {
+		f, err := framer.ReadFrame()
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch f := f.(type) {
+		case *http2.SettingsFrame:
+			if v, ok := f.Value(http2.SettingMaxConcurrentStreams); ok && v != 50 {
+				t.Fatalf("expected HTTP2 config to apply, got MaxConcurrentStreams %d", v)
+			}
+			if !f.IsAck() {
+				framer.WriteSettingsAck()
+			}
+		case *http2.DataFrame:
+			if f.StreamID != stream {
+				continue
+			}
+			body = append(body, f.Data()...)
+			if f.StreamEnded() {
+				return body
+			}
+		}
+	}
+}
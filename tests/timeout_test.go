@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/saintmili/mows"
+)
+
+func TestTimeoutExceeded(t *testing.T) {
+	app := mows.New()
+	app.Use(mows.Timeout(20 * time.Millisecond))
+
+	cancelled := make(chan struct{})
+	app.GET("/slow", func(c *mows.Context) error {
+		<-c.Request.Context().Done()
+		close(cancelled)
+		return c.Text(200, "too late")
+	})
+
+	req := httptest.NewRequest("GET", "/slow", nil)
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	if w.Code != 503 {
+		t.Fatalf("expected 503 got %d", w.Code)
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("handler context was not cancelled")
+	}
+}
+
+func TestTimeoutParentCancelled(t *testing.T) {
+	app := mows.New()
+	app.Use(mows.Timeout(time.Second))
+
+	app.GET("/slow", func(c *mows.Context) error {
+		<-c.Request.Context().Done()
+		return c.Text(200, "too late")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest("GET", "/slow", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+	time.AfterFunc(20*time.Millisecond, cancel)
+	app.ServeHTTP(w, req)
+
+	if w.Code != 200 || w.Body.Len() != 0 {
+		t.Fatalf("expected no response for a cancelled request, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestTimeoutRouteOverride(t *testing.T) {
+	app := mows.New()
+	app.Use(mows.Timeout(10 * time.Millisecond))
+
+	exports := app.Group("/exports", mows.Timeout(time.Second))
+	exports.GET("/csv", func(c *mows.Context) error {
+		time.Sleep(50 * time.Millisecond)
+		return c.Text(200, "done")
+	})
+
+	req := httptest.NewRequest("GET", "/exports/csv", nil)
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	if w.Code != 200 || w.Body.String() != "done" {
+		t.Fatalf("expected 200 done got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestTimeoutHandlesErrorOnce(t *testing.T) {
+	loggers := map[string]mows.Middleware{
+		"Logger":    mows.LoggerWithConfig(mows.LoggerConfig{Output: io.Discard}),
+		"AccessLog": mows.AccessLog(mows.AccessLogConfig{Output: io.Discard}),
+	}
+	for name, logger := range loggers {
+		app := mows.New()
+		app.Use(mows.Timeout(time.Second), logger)
+		app.GET("/fail", func(c *mows.Context) error {
+			return mows.NewHTTPError(http.StatusBadRequest, "bad input")
+		})
+
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", "/fail", nil))
+
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400 got %d", name, w.Code)
+		}
+		dec := json.NewDecoder(w.Body)
+		var body map[string]any
+		if err := dec.Decode(&body); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if dec.More() {
+			t.Fatalf("%s: expected a single error object, got more after %v", name, body)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package tests
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/saintmili/mows"
+)
+
+// writeCert creates a self-signed certificate for name and writes it
+// to dir, returning the certificate and key paths.
+func writeCert(t *testing.T, dir, name string) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile := filepath.Join(dir, name+".crt")
+	keyFile := filepath.Join(dir, name+".key")
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+	return certFile, keyFile
+}
+
+func TestCertManagerSNIAndReload(t *testing.T) {
+	dir := t.TempDir()
+	certs := mows.NewCertManager()
+
+	aCert, aKey := writeCert(t, dir, "a.example.com")
+	bCert, bKey := writeCert(t, dir, "b.example.com")
+	if err := certs.Add(aCert, aKey); err != nil {
+		t.Fatal(err)
+	}
+	if err := certs.Add(bCert, bKey); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := certs.GetCertificate(&tls.ClientHelloInfo{ServerName: "b.example.com"})
+	if err != nil || got.Leaf.Subject.CommonName != "b.example.com" {
+		t.Fatalf("expected b.example.com certificate, got %v %v", got, err)
+	}
+
+	before := got.Leaf.SerialNumber
+	writeCert(t, dir, "b.example.com")
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(bCert, future, future)
+
+	if err := certs.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, _ = certs.GetCertificate(&tls.ClientHelloInfo{ServerName: "b.example.com"})
+	if got.Leaf.SerialNumber.Cmp(before) == 0 {
+		t.Fatal("expected rotated certificate after reload")
+	}
+
+	if err := certs.Reload(); err != nil {
+		t.Fatalf("expected nil error when nothing changed, got %v", err)
+	}
+
+	os.Remove(aKey)
+	if err := certs.Reload(); err == nil {
+		t.Fatal("expected error for a missing key file")
+	}
+}
+
+func TestClientCertAuth(t *testing.T) {
+	app := mows.New()
+	app.Use(mows.ClientCertAuth(mows.ClientCertConfig{
+		Subjects: []string{"billing"},
+	}))
+
+	app.GET("/internal", func(c *mows.Context) error {
+		return c.Text(200, c.ClientCertificate().Subject.CommonName)
+	})
+
+	send := func(cn string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/internal", nil)
+		if cn != "" {
+			cert := &x509.Certificate{Subject: pkix.Name{CommonName: cn}}
+			req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := send(""); w.Code != 401 {
+		t.Fatalf("expected 401 got %d", w.Code)
+	}
+	if w := send("orders"); w.Code != 403 {
+		t.Fatalf("expected 403 got %d", w.Code)
+	}
+	if w := send("billing"); w.Code != 200 || w.Body.String() != "billing" {
+		t.Fatalf("expected 200 billing got %d %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,264 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saintmili/mows"
+)
+
+type paging struct {
+	Page int `query:"page" validate:"omitempty,min=1"`
+}
+
+type createOrderRequest struct {
+	paging
+	Store   string        `uri:"store"`
+	DryRun  bool          `query:"dry_run"`
+	Tags    []string      `query:"tag"`
+	Version *int          `header:"If-Match"`
+	Timeout time.Duration `header:"X-Timeout"`
+	Item    string        `json:"item" validate:"required"`
+	Qty     int           `json:"qty" validate:"min=1,max=100"`
+}
+
+type order struct {
+	Store  string   `json:"store"`
+	Item   string   `json:"item"`
+	Qty    int      `json:"qty"`
+	DryRun bool     `json:"dry_run"`
+	Tags   []string `json:"tags"`
+	Page   int      `json:"page"`
+}
+
+type created struct {
+	order
+}
+
+func (created) StatusCode() int { return http.StatusCreated }
+
+type deleted struct{}
+
+func (deleted) StatusCode() int { return http.StatusNoContent }
+
+type deleteOrderRequest struct {
+	ID int `uri:"id"`
+}
+
+func typedApp(errs *[]error) *mows.Engine {
+	app := mows.New()
+	app.SetErrorHandler(func(c *mows.Context, err error) {
+		*errs = append(*errs, err)
+		var httpErr *mows.HTTPError
+		code := 500
+		if errors.As(err, &httpErr) {
+			code = httpErr.Code
+		}
+		c.JSON(code, map[string]string{"error": err.Error()})
+	})
+
+	app.POST("/stores/:store/orders", mows.Typed(func(c *mows.Context, req createOrderRequest) (created, error) {
+		if req.Version != nil && *req.Version != 3 {
+			return created{}, mows.NewHTTPError(http.StatusPreconditionFailed, "")
+		}
+		if req.Timeout != 0 && req.Timeout != 2*time.Second {
+			return created{}, errors.New("unexpected timeout")
+		}
+		return created{order{
+			Store:  req.Store,
+			Item:   req.Item,
+			Qty:    req.Qty,
+			DryRun: req.DryRun,
+			Tags:   req.Tags,
+			Page:   req.Page,
+		}}, nil
+	})).Summary("Create an order")
+
+	app.DELETE("/orders/:id", mows.Typed(func(c *mows.Context, req deleteOrderRequest) (deleted, error) {
+		if req.ID != 7 {
+			return deleted{}, mows.NewHTTPError(http.StatusNotFound, "")
+		}
+		return deleted{}, nil
+	}))
+
+	app.GET("/orders", mows.Typed(func(c *mows.Context, req struct{}) ([]order, error) {
+		return []order{{Item: "tea"}}, nil
+	}))
+	return app
+}
+
+func TestTypedBindsAndEncodes(t *testing.T) {
+	var errs []error
+	app := typedApp(&errs)
+
+	req := httptest.NewRequest("POST", "/stores/s1/orders?dry_run=true&tag=a&tag=b&page=2",
+		strings.NewReader(`{"item":"tea","qty":2,"Store":"smuggled"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", "3")
+	req.Header.Set("X-Timeout", "2s")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201 got %d %s %v", w.Code, w.Body.String(), errs)
+	}
+	var got order
+	json.Unmarshal(w.Body.Bytes(), &got)
+	want := order{Store: "s1", Item: "tea", Qty: 2, DryRun: true, Tags: []string{"a", "b"}, Page: 2}
+	if got.Store != want.Store || got.Item != want.Item || got.Qty != want.Qty || !got.DryRun || !slices.Equal(got.Tags, want.Tags) || got.Page != 2 {
+		t.Fatalf("expected %+v got %+v", want, got)
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("DELETE", "/orders/7", nil))
+	if w.Code != http.StatusNoContent || w.Body.Len() != 0 {
+		t.Fatalf("expected empty 204 got %d %q", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/orders", nil))
+	if w.Code != 200 || !strings.Contains(w.Body.String(), `"item":"tea"`) {
+		t.Fatalf("expected 200 with orders got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestTypedErrors(t *testing.T) {
+	var errs []error
+	app := typedApp(&errs)
+
+	send := func(method, target, contentType, body string, header map[string]string) int {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := send("POST", "/stores/s1/orders", "application/json", `{"qty":0}`, nil); code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 got %d", code)
+	}
+	var validationErr *mows.ValidationError
+	if !errors.As(errs[len(errs)-1], &validationErr) {
+		t.Fatalf("expected ValidationError got %v", errs[len(errs)-1])
+	}
+	fields := []string{}
+	for _, f := range validationErr.Fields {
+		fields = append(fields, f.Field+":"+f.Rule)
+	}
+	if !slices.Equal(fields, []string{"item:required", "qty:min"}) {
+		t.Fatalf("unexpected field errors %v", fields)
+	}
+
+	if code := send("POST", "/stores/s1/orders?page=-1", "application/json", `{"item":"tea","qty":1}`, nil); code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected embedded query validation to fail, got %d", code)
+	}
+	if validationErr := new(mows.ValidationError); !errors.As(errs[len(errs)-1], &validationErr) || validationErr.Fields[0].Field != "page" {
+		t.Fatalf("expected page field error, got %v", errs[len(errs)-1])
+	}
+
+	cases := []struct {
+		method, target, contentType, body string
+		header                            map[string]string
+		want                              int
+		in, field                         string
+	}{
+		{"POST", "/stores/s1/orders", "application/json", `{"item":`, nil, 400, "body", ""},
+		{"POST", "/stores/s1/orders", "text/plain", `item=tea`, nil, 415, "body", ""},
+		{"POST", "/stores/s1/orders?dry_run=maybe", "application/json", `{"item":"tea","qty":1}`, nil, 400, "query", "dry_run"},
+		{"POST", "/stores/s1/orders", "application/json", `{"item":"tea","qty":1}`, map[string]string{"If-Match": "x"}, 400, "header", "If-Match"},
+		{"DELETE", "/orders/abc", "", "", nil, 400, "path", "id"},
+	}
+	for _, tc := range cases {
+		if code := send(tc.method, tc.target, tc.contentType, tc.body, tc.header); code != tc.want {
+			t.Fatalf("%s %s: expected %d got %d", tc.method, tc.target, tc.want, code)
+		}
+		var bindErr *mows.BindingError
+		if !errors.As(errs[len(errs)-1], &bindErr) || bindErr.In != tc.in || bindErr.Field != tc.field {
+			t.Fatalf("%s %s: unexpected error %v", tc.method, tc.target, errs[len(errs)-1])
+		}
+	}
+
+	if code := send("POST", "/stores/s1/orders", "application/json", `{"item":"tea","qty":1}`, map[string]string{"If-Match": "2"}); code != http.StatusPreconditionFailed {
+		t.Fatalf("expected handler error to pass through, got %d", code)
+	}
+	if code := send("DELETE", "/orders/8", "", "", nil); code != http.StatusNotFound {
+		t.Fatalf("expected 404 got %d", code)
+	}
+}
+
+func TestTypedDocumentsRoutes(t *testing.T) {
+	var errs []error
+	doc := typedApp(&errs).OpenAPI()
+
+	create := doc.Paths["/stores/{store}/orders"]["post"]
+	if create.Summary != "Create an order" || create.RequestBody == nil {
+		t.Fatalf("unexpected create operation %+v", create)
+	}
+	if resp := create.Responses["201"]; resp == nil || resp.Content["application/json"].Schema.Ref != "#/components/schemas/created" {
+		t.Fatalf("expected 201 created response, got %+v", create.Responses)
+	}
+	var names []string
+	for _, p := range create.Parameters {
+		names = append(names, p.In+":"+p.Name)
+	}
+	if !slices.Equal(names, []string{"query:page", "path:store", "query:dry_run", "query:tag", "header:If-Match", "header:X-Timeout"}) {
+		t.Fatalf("unexpected parameters %v", names)
+	}
+
+	remove := doc.Paths["/orders/{id}"]["delete"]
+	if resp := remove.Responses["204"]; resp == nil || resp.Content != nil || remove.Parameters[0].Schema.Type != "integer" {
+		t.Fatalf("unexpected delete operation %+v", remove)
+	}
+
+	list := doc.Paths["/orders"]["get"]
+	if list.Parameters != nil || list.Responses["200"].Content["application/json"].Schema.Type != "array" {
+		t.Fatalf("unexpected list operation %+v", list)
+	}
+}
+
+func TestTypedBody(t *testing.T) {
+	app := mows.New()
+	var errs []error
+	app.SetErrorHandler(func(c *mows.Context, err error) {
+		errs = append(errs, err)
+		var httpErr *mows.HTTPError
+		if errors.As(err, &httpErr) {
+			c.Writer.WriteHeader(httpErr.Code)
+		}
+	})
+	app.POST("/notes", mows.TypedWithConfig(mows.TypedConfig{MaxBodySize: 64}, func(c *mows.Context, req struct {
+		Text string `json:"text"`
+	}) (map[string]string, error) {
+		return map[string]string{"text": req.Text}, nil
+	}))
+
+	// a body without a Content-Type is read as JSON
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("POST", "/notes", strings.NewReader(`{"text":"hi"}`)))
+	if w.Code != 200 || !strings.Contains(w.Body.String(), `"text":"hi"`) {
+		t.Fatalf("expected 200 got %d %s", w.Code, w.Body.String())
+	}
+
+	req := httptest.NewRequest("POST", "/notes", strings.NewReader(`{"text":"`+strings.Repeat("x", 100)+`"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413 got %d", w.Code)
+	}
+	var bindErr *mows.BindingError
+	if !errors.As(errs[len(errs)-1], &bindErr) || bindErr.In != "body" {
+		t.Fatalf("expected body BindingError got %v", errs[len(errs)-1])
+	}
+}
@@ -0,0 +1,286 @@
+package mows
+
+import (
+	"bytes"
+	"context"
+	"maps"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TimeoutConfig defines the configuration for the Timeout middleware.
+type TimeoutConfig struct {
+	// Timeout is the maximum duration of the handler chain.
+	Timeout time.Duration
+
+	// StatusCode is returned when the timeout is exceeded.
+	// Defaults to 503 Service Unavailable; 504 is also common.
+	StatusCode int
+
+	// Message is the error message passed to the error handler.
+	// Defaults to the status text of StatusCode.
+	Message string
+}
+
+// Timeout returns a middleware that limits how long the rest of the
+// chain may run.
+//
+// See TimeoutWithConfig for details.
+//
+// Example:
+//
+//	app.Use(mows.Timeout(5 * time.Second))
+func Timeout(d time.Duration) Middleware {
+	return TimeoutWithConfig(TimeoutConfig{Timeout: d})
+}
+
+// TimeoutWithConfig returns a Timeout middleware with custom settings.
+//
+// The request context gets a deadline, so database calls and outgoing
+// requests made with c.Request.Context() are cancelled when it expires.
+// The handler runs on its own goroutine and its response is buffered;
+// when the deadline is exceeded the buffered response is discarded,
+// later writes are dropped, and an HTTPError with StatusCode is passed
+// to the error handler. When the client goes away first, nothing is
+// written.
+//
+// A nested Timeout replaces the deadline of an outer one, so
+// long-running routes can be given more time:
+//
+//	app.Use(mows.Timeout(5 * time.Second))
+//
+//	exports := app.Group("/exports", mows.Timeout(10*time.Minute))
+//
+// Because responses are buffered, streaming handlers do not flush
+// until they return.
+func TimeoutWithConfig(config TimeoutConfig) Middleware {
+	if config.Timeout <= 0 {
+		panic("mows: timeout must be positive")
+	}
+	if config.StatusCode == 0 {
+		config.StatusCode = http.StatusServiceUnavailable
+	}
+	if config.Message == "" {
+		config.Message = http.StatusText(config.StatusCode)
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			// an outer Timeout is already running: override its deadline
+			if tc, ok := c.Request.Context().Value(timeoutContextKey{}).(*timeoutContext); ok {
+				tc.reset(config.Timeout)
+				return next(c)
+			}
+
+			tc := newTimeoutContext(c.Request.Context(), config.Timeout)
+			defer tc.stop()
+
+			tw := &timeoutWriter{
+				header: c.Writer.Header().Clone(),
+				status: http.StatusOK,
+			}
+
+			// the handler works on its own copy of the Context so that a
+			// late handler can never touch the real response
+			hc := *c
+			hc.Writer = newResponseWriter(tw)
+			hc.Request = c.Request.WithContext(tc)
+			hc.keys = maps.Clone(c.keys)
+
+			done := make(chan error, 1)
+			panicked := make(chan any, 1)
+
+			go func() {
+				defer func() {
+					if p := recover(); p != nil {
+						// keep the handler's stack, which is lost once
+						// the panic is re-raised on this goroutine
+						if p != http.ErrAbortHandler {
+							p = newPanicError(p)
+						}
+						panicked <- p
+					}
+				}()
+				done <- next(&hc)
+			}()
+
+			select {
+			case err := <-done:
+				tw.flushTo(c.Writer)
+				c.Params = hc.Params
+				c.Status = hc.Status
+				c.keys = hc.keys
+				// middleware such as Logger may have handled err already
+				c.errorHandled = hc.errorHandled
+				return err
+
+			case p := <-panicked:
+				tw.discard()
+				panic(p)
+
+			case <-tc.Done():
+				tw.discard()
+				if !tc.expired.Load() {
+					// the client went away or the server is shutting down;
+					// there is no one to respond to
+					return nil
+				}
+				return &HTTPError{
+					Code:    config.StatusCode,
+					Message: config.Message,
+					Err:     context.DeadlineExceeded,
+				}
+			}
+		}
+	}
+}
+
+// timeoutContextKey is the context key used to find an active timeoutContext.
+type timeoutContextKey struct{}
+
+// timeoutContext is a context whose deadline can be moved while the
+// request is in flight.
+type timeoutContext struct {
+	context.Context
+	cancel context.CancelCauseFunc
+
+	mu       sync.Mutex
+	start    time.Time
+	deadline time.Time
+	timer    *time.Timer
+	expired  atomic.Bool
+}
+
+func newTimeoutContext(parent context.Context, d time.Duration) *timeoutContext {
+	ctx, cancel := context.WithCancelCause(parent)
+	tc := &timeoutContext{
+		Context:  ctx,
+		cancel:   cancel,
+		start:    time.Now(),
+		deadline: time.Now().Add(d),
+	}
+	tc.timer = time.AfterFunc(d, tc.expire)
+	return tc
+}
+
+// expire cancels the context because the deadline passed.
+func (tc *timeoutContext) expire() {
+	tc.expired.Store(true)
+	tc.cancel(context.DeadlineExceeded)
+}
+
+// reset moves the deadline to d after the start of the request.
+func (tc *timeoutContext) reset(d time.Duration) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	if tc.expired.Load() {
+		return
+	}
+	tc.deadline = tc.start.Add(d)
+	tc.timer.Reset(time.Until(tc.deadline))
+}
+
+// stop releases the timer and the context.
+func (tc *timeoutContext) stop() {
+	tc.timer.Stop()
+	tc.cancel(context.Canceled)
+}
+
+// Deadline returns the current deadline, or the parent's if it is earlier.
+func (tc *timeoutContext) Deadline() (time.Time, bool) {
+	tc.mu.Lock()
+	deadline := tc.deadline
+	tc.mu.Unlock()
+
+	if parent, ok := tc.Context.Deadline(); ok && parent.Before(deadline) {
+		return parent, true
+	}
+	return deadline, true
+}
+
+// Err reports context.DeadlineExceeded once the deadline has passed.
+func (tc *timeoutContext) Err() error {
+	err := tc.Context.Err()
+	if err != nil && tc.expired.Load() {
+		return context.DeadlineExceeded
+	}
+	return err
+}
+
+// Value returns the timeoutContext itself for timeoutContextKey.
+func (tc *timeoutContext) Value(key any) any {
+	if key == (timeoutContextKey{}) {
+		return tc
+	}
+	return tc.Context.Value(key)
+}
+
+// timeoutWriter buffers a response until the handler finishes.
+//
+// Once discarded, further writes fail with http.ErrHandlerTimeout.
+type timeoutWriter struct {
+	mu          sync.Mutex
+	header      http.Header
+	buf         bytes.Buffer
+	status      int
+	wroteHeader bool
+	discarded   bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.discarded || tw.wroteHeader {
+		return
+	}
+	tw.wroteHeader = true
+	tw.status = code
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.discarded {
+		return 0, http.ErrHandlerTimeout
+	}
+	tw.wroteHeader = true
+	return tw.buf.Write(b)
+}
+
+// discard drops the buffered response and rejects later writes.
+func (tw *timeoutWriter) discard() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	tw.discarded = true
+	tw.buf.Reset()
+}
+
+// flushTo copies the buffered response to w.
+func (tw *timeoutWriter) flushTo(w *responseWriter) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	dst := w.Header()
+	for k := range dst {
+		if _, ok := tw.header[k]; !ok {
+			delete(dst, k)
+		}
+	}
+	maps.Copy(dst, tw.header)
+
+	if !tw.wroteHeader {
+		return
+	}
+	w.WriteHeader(tw.status)
+	w.Write(tw.buf.Bytes())
+}
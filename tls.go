@@ -0,0 +1,362 @@
+package mows
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultTLSConfig returns a tls.Config with secure defaults.
+//
+// It requires TLS 1.2 or newer, restricts TLS 1.2 to forward-secret
+// AEAD cipher suites, and prefers modern curves.
+func DefaultTLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		CipherSuites: []uint16{
+			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+		},
+		CurvePreferences: []tls.CurveID{tls.X25519, tls.CurveP256},
+	}
+}
+
+// RunTLSConfig starts the HTTPS server with a custom tls.Config.
+//
+// The config must provide certificates through Certificates or
+// GetCertificate, for example via CertManager.TLSConfig.
+//
+// Example:
+//
+//	certs := mows.NewCertManager()
+//	certs.Add("cert.pem", "key.pem")
+//	app.RunTLSConfig(":443", certs.TLSConfig())
+func (e *Engine) RunTLSConfig(addr string, config *tls.Config) error {
+	l, err := e.listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return e.serve(l, func(srv *http.Server) error {
+		srv.TLSConfig = config
+		e.logger.Info("server started", "addr", l.Addr().String(), "tls", true)
+		return srv.ServeTLS(l, "", "")
+	})
+}
+
+// ClientCAPool loads PEM-encoded CA certificates used to verify
+// client certificates in mutual TLS.
+//
+// Example:
+//
+//	pool, _ := mows.ClientCAPool("clients-ca.pem")
+//	config := certs.TLSConfig()
+//	config.ClientCAs = pool
+//	config.ClientAuth = tls.RequireAndVerifyClientCert
+func ClientCAPool(files ...string) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	for _, file := range files {
+		pem, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("mows: no certificates found in %s", file)
+		}
+	}
+	return pool, nil
+}
+
+// CertManager serves TLS certificates that can be replaced at runtime.
+//
+// Certificates are loaded from files and selected by SNI server name.
+// Reload and Watch pick up rotated files and swap them atomically, so
+// new handshakes use the new certificate without restarting the server.
+type CertManager struct {
+	mu      sync.Mutex
+	pairs   []*certPair
+	current atomic.Pointer[[]*tls.Certificate]
+}
+
+// certPair is a certificate/key file pair and the modification times
+// it was last loaded with.
+type certPair struct {
+	certFile string
+	keyFile  string
+	certMod  time.Time
+	keyMod   time.Time
+	cert     *tls.Certificate
+}
+
+// NewCertManager creates an empty CertManager.
+func NewCertManager() *CertManager {
+	m := &CertManager{}
+	m.current.Store(&[]*tls.Certificate{})
+	return m
+}
+
+// Add loads a certificate/key pair and adds it to the manager.
+//
+// The first certificate added is the default for clients that do not
+// send a matching server name.
+func (m *CertManager) Add(certFile, keyFile string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p := &certPair{certFile: certFile, keyFile: keyFile}
+	if err := p.load(); err != nil {
+		return err
+	}
+
+	m.pairs = append(m.pairs, p)
+	m.publish()
+	return nil
+}
+
+// Reload reloads every certificate whose files changed since they were
+// last loaded. On error the previous certificate stays in use.
+func (m *CertManager) Reload() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var errs []error
+	changed := false
+	for _, p := range m.pairs {
+		ok, err := p.modified()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if err := p.load(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		changed = true
+	}
+
+	if changed {
+		m.publish()
+	}
+	return errors.Join(errs...)
+}
+
+// Watch calls Reload every interval until ctx is done.
+// Reload errors are logged with slog.Default().
+//
+// Example:
+//
+//	ctx, cancel := context.WithCancel(context.Background())
+//	go certs.Watch(ctx, time.Minute)
+//	app.OnShutdown(func(context.Context) error { cancel(); return nil })
+func (m *CertManager) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.Reload(); err != nil {
+				slog.Error("certificate reload failed", "error", err)
+			}
+		}
+	}
+}
+
+// GetCertificate selects a certificate for the TLS handshake.
+//
+// It can be used as tls.Config.GetCertificate.
+func (m *CertManager) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	certs := *m.current.Load()
+	if len(certs) == 0 {
+		return nil, errors.New("mows: no certificates loaded")
+	}
+
+	name := strings.ToLower(strings.TrimSuffix(hello.ServerName, "."))
+	if name != "" {
+		for _, cert := range certs {
+			if matchesServerName(cert.Leaf, name) {
+				return cert, nil
+			}
+		}
+	}
+
+	return certs[0], nil
+}
+
+// TLSConfig returns DefaultTLSConfig with GetCertificate set to m.
+func (m *CertManager) TLSConfig() *tls.Config {
+	config := DefaultTLSConfig()
+	config.GetCertificate = m.GetCertificate
+	return config
+}
+
+// publish atomically replaces the certificates used for handshakes.
+func (m *CertManager) publish() {
+	certs := make([]*tls.Certificate, len(m.pairs))
+	for i, p := range m.pairs {
+		certs[i] = p.cert
+	}
+	m.current.Store(&certs)
+}
+
+// load reads the certificate and key files.
+func (p *certPair) load() error {
+	certMod, keyMod, err := p.modTimes()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(p.certFile, p.keyFile)
+	if err != nil {
+		return err
+	}
+
+	p.cert = &cert
+	p.certMod = certMod
+	p.keyMod = keyMod
+	return nil
+}
+
+// modified reports whether either file changed since the last load.
+func (p *certPair) modified() (bool, error) {
+	certMod, keyMod, err := p.modTimes()
+	if err != nil {
+		return false, err
+	}
+	return !certMod.Equal(p.certMod) || !keyMod.Equal(p.keyMod), nil
+}
+
+func (p *certPair) modTimes() (time.Time, time.Time, error) {
+	certInfo, err := os.Stat(p.certFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	keyInfo, err := os.Stat(p.keyFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return certInfo.ModTime(), keyInfo.ModTime(), nil
+}
+
+// matchesServerName reports whether cert is valid for name, including
+// single-label wildcards such as *.example.com.
+func matchesServerName(cert *x509.Certificate, name string) bool {
+	if cert == nil {
+		return false
+	}
+
+	for _, dns := range cert.DNSNames {
+		dns = strings.ToLower(dns)
+		if dns == name {
+			return true
+		}
+		if suffix, ok := strings.CutPrefix(dns, "*."); ok {
+			if _, rest, found := strings.Cut(name, "."); found && rest == suffix {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// ClientCertificate returns the verified client certificate of a
+// mutual TLS connection, or nil if the client did not present one.
+func (c *Context) ClientCertificate() *x509.Certificate {
+	if c.Request.TLS == nil || len(c.Request.TLS.VerifiedChains) == 0 {
+		return nil
+	}
+	return c.Request.TLS.VerifiedChains[0][0]
+}
+
+// ClientCertConfig defines the configuration for the ClientCertAuth middleware.
+//
+// A certificate is authorized if it matches any of the allowed values.
+type ClientCertConfig struct {
+	// Subjects lists allowed subject common names.
+	Subjects []string
+
+	// DNSNames lists allowed DNS subject alternative names.
+	DNSNames []string
+
+	// URIs lists allowed URI subject alternative names,
+	// e.g. SPIFFE IDs such as spiffe://cluster/ns/billing/sa/api.
+	URIs []string
+
+	// Emails lists allowed email subject alternative names.
+	Emails []string
+
+	// Authorize is an optional custom check. Certificates it accepts
+	// are authorized even if they match none of the lists above.
+	Authorize func(*x509.Certificate) bool
+}
+
+// ClientCertAuth returns a middleware that authorizes requests by the
+// verified client certificate.
+//
+// Requests without a verified certificate receive 401 Unauthorized;
+// certificates that match none of the allowed values receive 403 Forbidden.
+//
+// Example:
+//
+//	internal := app.Group("/internal", mows.ClientCertAuth(mows.ClientCertConfig{
+//	    URIs: []string{"spiffe://cluster/ns/billing/sa/api"},
+//	}))
+func ClientCertAuth(config ClientCertConfig) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			cert := c.ClientCertificate()
+			if cert == nil {
+				return NewHTTPError(http.StatusUnauthorized, "client certificate required")
+			}
+
+			if !config.allows(cert) {
+				return NewHTTPError(http.StatusForbidden, "client certificate not allowed")
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// allows reports whether cert matches the configuration.
+func (config ClientCertConfig) allows(cert *x509.Certificate) bool {
+	if slices.Contains(config.Subjects, cert.Subject.CommonName) {
+		return true
+	}
+	for _, dns := range cert.DNSNames {
+		if slices.Contains(config.DNSNames, dns) {
+			return true
+		}
+	}
+	for _, uri := range cert.URIs {
+		if slices.Contains(config.URIs, uri.String()) {
+			return true
+		}
+	}
+	for _, email := range cert.EmailAddresses {
+		if slices.Contains(config.Emails, email) {
+			return true
+		}
+	}
+	return config.Authorize != nil && config.Authorize(cert)
+}
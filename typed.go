@@ -0,0 +1,459 @@
+package mows
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// StatusCoder is implemented by typed handler responses that choose
+// their status code. Other responses are sent with 200 OK.
+//
+// The status of the zero value is used in the OpenAPI document.
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// BindingError is returned by typed handlers when a request value
+// cannot be decoded. It is wrapped in a 400 Bad Request HTTPError, or
+// 415 Unsupported Media Type for bodies that are not JSON.
+type BindingError struct {
+	// In is "path", "query", "header" or "body".
+	In string
+
+	// Field is the parameter name, empty for the body.
+	Field string
+
+	Err error
+}
+
+// Error implements the error interface.
+func (e *BindingError) Error() string {
+	if e.Field == "" {
+		return "invalid " + e.In + ": " + e.Err.Error()
+	}
+	return "invalid " + e.In + " parameter " + e.Field + ": " + e.Err.Error()
+}
+
+// Unwrap returns the decoding error.
+func (e *BindingError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError is returned by typed handlers when a request fails
+// its validate tags. It is wrapped in a 422 Unprocessable Entity
+// HTTPError.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+// FieldError describes a field that failed validation.
+type FieldError struct {
+	// Field is the name the client used, e.g. "email" or
+	// "address.city", taken from json, uri, query and header tags.
+	Field string
+
+	// Rule is the failed validate rule, e.g. "min".
+	Rule string
+
+	// Param is the parameter of the rule, e.g. "3".
+	Param string
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		rule := f.Rule
+		if f.Param != "" {
+			rule += "=" + f.Param
+		}
+		parts[i] = f.Field + " (" + rule + ")"
+	}
+	return "validation failed: " + strings.Join(parts, ", ")
+}
+
+// TypedConfig defines the configuration for typed handlers.
+type TypedConfig struct {
+	// MaxBodySize is the maximum size in bytes of a request body.
+	// Larger bodies fail with 413 Request Entity Too Large.
+	// Defaults to 10 MiB.
+	MaxBodySize int64
+}
+
+// handlerTypes describes the request and response of a typed handler.
+type handlerTypes struct {
+	request  reflect.Type
+	response reflect.Type
+	status   int
+
+	// name is the name of the adapted function, reported by Engine.Routes.
+	name string
+}
+
+// typedHandlers maps the handlers created by Typed, by closureKey, to
+// their types, so registration can document them.
+var typedHandlers sync.Map
+
+// Typed adapts a function taking a request struct and returning a
+// response into a HandlerFunc.
+//
+// The request is bound from the path, query and headers by uri, query
+// and header tags, and from the JSON body by json tags, then validated
+// with validate tags. Bodies are read as JSON when the Content-Type is
+// JSON or missing; other media types fail with 415 Unsupported Media
+// Type, and bodies over 10 MiB with 413 (see TypedWithConfig). The
+// response is encoded as JSON with the status of StatusCoder, or
+// 200 OK. Errors returned by fn are passed to the error handler
+// unchanged; binding and validation failures are *BindingError and
+// *ValidationError wrapped in an HTTPError.
+//
+// Routes using Typed are documented in the OpenAPI document with the
+// request and response types.
+//
+// Example:
+//
+//	type CreateOrder struct {
+//	    StoreID string `uri:"store"`
+//	    DryRun  bool   `query:"dry_run"`
+//	    Item    string `json:"item" validate:"required"`
+//	    Qty     int    `json:"qty" validate:"min=1,max=100"`
+//	}
+//
+//	func createOrder(c *mows.Context, req CreateOrder) (Order, error) {
+//	    ...
+//	}
+//
+//	app.POST("/stores/:store/orders", mows.Typed(createOrder))
+func Typed[Req, Resp any](fn func(c *Context, req Req) (Resp, error)) HandlerFunc {
+	return TypedWithConfig(TypedConfig{}, fn)
+}
+
+// TypedWithConfig adapts fn like Typed, with custom settings.
+//
+// Example:
+//
+//	app.POST("/uploads", mows.TypedWithConfig(mows.TypedConfig{MaxBodySize: 100 << 20}, upload))
+func TypedWithConfig[Req, Resp any](config TypedConfig, fn func(c *Context, req Req) (Resp, error)) HandlerFunc {
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = 10 << 20
+	}
+
+	types := handlerTypes{
+		request:  reflect.TypeFor[Req](),
+		response: reflect.TypeFor[Resp](),
+		status:   responseStatus[Resp](),
+		name:     funcName(fn),
+	}
+
+	h := HandlerFunc(func(c *Context) error {
+		var req Req
+		if err := bind(c, &req, config.MaxBodySize); err != nil {
+			return err
+		}
+
+		resp, err := fn(c, req)
+		if err != nil {
+			return err
+		}
+
+		status := http.StatusOK
+		if sc, ok := any(resp).(StatusCoder); ok {
+			status = sc.StatusCode()
+		}
+		if status == http.StatusNoContent || status == http.StatusNotModified {
+			c.Writer.WriteHeader(status)
+			return nil
+		}
+		return c.JSON(status, resp)
+	})
+
+	typedHandlers.Store(closureKey(h), &types)
+	return h
+}
+
+// describeHandler returns the types of a handler created by Typed, or
+// nil for other handlers.
+func describeHandler(h HandlerFunc) *handlerTypes {
+	if types, ok := typedHandlers.Load(closureKey(h)); ok {
+		return types.(*handlerTypes)
+	}
+	return nil
+}
+
+// document adds the types of a typed handler to route metadata.
+func (types *handlerTypes) document(meta *RouteMeta) {
+	if t := indirectType(types.request); t.Kind() != reflect.Struct || t.NumField() > 0 {
+		meta.Request = types.request
+	}
+
+	resp := RouteResponse{Status: types.status, Type: types.response}
+	if types.status == http.StatusNoContent || types.status == http.StatusNotModified {
+		resp.Type = nil
+	}
+	meta.Responses = append(meta.Responses, resp)
+}
+
+// responseStatus returns the status of the zero value of Resp.
+func responseStatus[Resp any]() int {
+	var resp Resp
+	if t := reflect.TypeFor[Resp](); t.Kind() == reflect.Pointer {
+		resp = reflect.New(t.Elem()).Interface().(Resp)
+	}
+	if sc, ok := any(resp).(StatusCoder); ok {
+		return sc.StatusCode()
+	}
+	return http.StatusOK
+}
+
+// bind decodes and validates the request into req, a pointer.
+func bind(c *Context, req any, maxBodySize int64) error {
+	v := reflect.ValueOf(req).Elem()
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	if err := bindBody(c, v, maxBodySize); err != nil {
+		return err
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	if err := bindParams(c, v); err != nil {
+		return err
+	}
+
+	err := c.engine.validate.Struct(v.Interface())
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return nil
+	}
+
+	validationErr := &ValidationError{}
+	for _, fe := range fieldErrs {
+		validationErr.Fields = append(validationErr.Fields, FieldError{
+			Field: clientFieldName(v.Type(), fe.StructNamespace()),
+			Rule:  fe.Tag(),
+			Param: fe.Param(),
+		})
+	}
+	return &HTTPError{Code: http.StatusUnprocessableEntity, Message: validationErr.Error(), Err: validationErr}
+}
+
+// bindBody decodes a JSON body of at most maxBodySize bytes into v.
+// Empty bodies are skipped; bodies without a Content-Type are JSON.
+func bindBody(c *Context, v reflect.Value, maxBodySize int64) error {
+	if c.Request.Body == nil {
+		return nil
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBodySize))
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			err := &BindingError{In: "body", Err: err}
+			return &HTTPError{Code: http.StatusRequestEntityTooLarge, Message: err.Error(), Err: err}
+		}
+		return err
+	}
+	if len(body) == 0 {
+		return nil
+	}
+
+	contentType := c.Request.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if contentType != "" && mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") {
+		err := &BindingError{In: "body", Err: errors.New("content-type must be application/json")}
+		return &HTTPError{Code: http.StatusUnsupportedMediaType, Message: err.Error(), Err: err}
+	}
+
+	if err := json.Unmarshal(body, v.Addr().Interface()); err != nil {
+		err := &BindingError{In: "body", Err: err}
+		return &HTTPError{Code: http.StatusBadRequest, Message: err.Error(), Err: err}
+	}
+	return nil
+}
+
+// bindParams sets the fields tagged uri, query and header. Fields whose
+// parameter is absent are reset, so the body cannot set them.
+func bindParams(c *Context, v reflect.Value) error {
+	query := c.Request.URL.Query()
+
+	for _, f := range structFields(v.Type()) {
+		in, name := paramTag(f)
+		if in == "" {
+			continue
+		}
+
+		var values []string
+		switch in {
+		case "path":
+			if value, ok := c.Params[name]; ok {
+				values = []string{value}
+			}
+		case "query":
+			values = query[name]
+		case "header":
+			values = c.Request.Header.Values(name)
+		}
+
+		field := fieldByIndex(v, f.Index)
+		if !field.CanSet() {
+			continue
+		}
+		if len(values) == 0 {
+			field.SetZero()
+			continue
+		}
+		if err := setValue(field, values); err != nil {
+			err := &BindingError{In: in, Field: name, Err: err}
+			return &HTTPError{Code: http.StatusBadRequest, Message: err.Error(), Err: err}
+		}
+	}
+	return nil
+}
+
+// fieldByIndex is reflect.Value.FieldByIndex, allocating nil embedded
+// pointers on the way.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+var (
+	durationType        = reflect.TypeFor[time.Duration]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// setValue parses parameter values into v. Slices take every value.
+func setValue(v reflect.Value, values []string) error {
+	if v.Kind() == reflect.Pointer {
+		ptr := reflect.New(v.Type().Elem())
+		if err := setValue(ptr.Elem(), values); err != nil {
+			return err
+		}
+		v.Set(ptr)
+		return nil
+	}
+
+	if reflect.PointerTo(v.Type()).Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(values[0]))
+	}
+
+	if v.Kind() == reflect.Slice {
+		s := reflect.MakeSlice(v.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setValue(s.Index(i), []string{value}); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+		return nil
+	}
+
+	value := values[0]
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == durationType {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return err
+			}
+			v.SetInt(int64(d))
+			return nil
+		}
+		n, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// clientFieldName converts a validator struct namespace such as
+// "CreateOrder.Items[0].SKU" to the names the client sent, such as
+// "items[0].sku".
+func clientFieldName(t reflect.Type, namespace string) string {
+	var names []string
+	parts := strings.Split(namespace, ".")[1:]
+	for i, part := range parts {
+		goName, suffix, _ := strings.Cut(part, "[")
+		if suffix != "" {
+			suffix = "[" + suffix
+		}
+
+		t = indirectType(t)
+		f, ok := reflect.StructField{}, false
+		if t.Kind() == reflect.Struct {
+			f, ok = t.FieldByName(goName)
+		}
+		if !ok {
+			// keep the remaining Go names rather than guess
+			names = append(names, parts[i:]...)
+			break
+		}
+		t = f.Type
+		for range strings.Count(suffix, "[") {
+			t = indirectType(t).Elem()
+		}
+
+		// fields of embedded structs are promoted, as in encoding/json
+		if f.Anonymous && f.Tag.Get("json") == "" && indirectType(f.Type).Kind() == reflect.Struct {
+			continue
+		}
+
+		name, _ := jsonName(f)
+		if in, param := paramTag(f); in != "" {
+			name = param
+		}
+		names = append(names, name+suffix)
+	}
+	return strings.Join(names, ".")
+}